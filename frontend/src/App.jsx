@@ -1,9 +1,35 @@
-import React, { useState, useCallback } from "react";
+import React, { useState, useCallback, useEffect, useRef } from "react";
 import { PDFDocument } from "pdf-lib";
 import PDFUploader from "./components/PDFUploader";
 import PDFViewer from "./components/PDFViewer";
 import Sidebar from "./components/Sidebar";
 import { generatePDF } from "./pdfGenerator";
+import {
+  isOnPage,
+  overlayPages,
+  pageMapForInsert,
+  pageMapForRemoval,
+  renumberOverlays,
+  renumberPageLabels,
+} from "./pages";
+import { BATES_DEFAULTS } from "./bates";
+import { extractNavigation, extractOutline } from "./navigation";
+import { insertTableOfContents, outlineEntries, parseTocEntries } from "./toc";
+import { bookmarkRanges, splitDocument } from "./split";
+import { tilePage } from "./poster";
+import { detectContentBoxes, detectPageInk } from "./pageInk";
+import { IMAGE_QUALITY } from "./imageQuality";
+import { countDigitalSignatures, countFileSignatures } from "./digitalSignatures";
+import { exportFormData, fillForm, parseFormData, toXfdf } from "./formData";
+import { downloadBlob } from "./download";
+import { normalizeOrientation, adjustMargins, cropToContent, removeBlankPages } from "./pageSetup";
+import { validateOverlays } from "./validation";
+import { describeError } from "./errors";
+import { UNITS } from "./units";
+import { mergeFileName } from "./mailMerge";
+import { editedFileName } from "./fileNames";
+import { checkPdfFile } from "./fileType";
+import { GENERATION_TIMEOUT_S } from "./config";
 
 export default function App() {
   const [pdfFile, setPdfFile] = useState(null);
@@ -13,10 +39,22 @@ export default function App() {
   const [currentPage, setCurrentPage] = useState(1);
   const [overlays, setOverlays] = useState([]);
   const [selectedOverlay, setSelectedOverlay] = useState(null);
-  const [activeTool, setActiveTool] = useState(null); // 'text' or 'image'
+  const [activeTool, setActiveTool] = useState(null); // 'text', 'highlight' or 'stamp'
   const [status, setStatus] = useState(null);
   const [downloadUrl, setDownloadUrl] = useState(null);
   const [processing, setProcessing] = useState(false);
+  const [mergeResults, setMergeResults] = useState([]); // [{ name, url, bytes }]
+  const [batchResults, setBatchResults] = useState([]); // [{ name, url, bytes } | { name, error }]
+  const [splitResults, setSplitResults] = useState([]); // [{ name, url, bytes }]
+  const [posterResult, setPosterResult] = useState(null); // { name, url }
+  const [progress, setProgress] = useState(null); // { label, done, total }
+  const [pageLabels, setPageLabels] = useState([]); // [{ startPage, style, prefix, start }]
+  const [imageQuality, setImageQuality] = useState("original"); // key of IMAGE_QUALITY
+  const [sanitize, setSanitize] = useState(false); // strip scripts, actions, attachments
+  const [scrub, setScrub] = useState(false); // strip author / tool metadata
+  const [skipFailures, setSkipFailures] = useState(false); // skip failing elements instead of aborting
+  const [formData, setFormData] = useState(null); // { name, values } imported to fill fields
+  const [diagnostics, setDiagnostics] = useState(null); // validateOverlays() results, null = unchecked
   const [pendingFile, setPendingFile] = useState(null); // file awaiting password
   const [pendingBytes, setPendingBytes] = useState(null); // bytes awaiting password
   const [showPasswordDialog, setShowPasswordDialog] = useState(false);
@@ -26,12 +64,7 @@ export default function App() {
     const loadOptions = password ? { password } : {};
     const doc = await PDFDocument.load(arrayBuffer, loadOptions);
     const pages = doc.getPages();
-
-    const info = {
-      pages: pages.length,
-      pageWidths: pages.map((p) => p.getSize().width),
-      pageHeights: pages.map((p) => p.getSize().height),
-    };
+    const info = documentInfo(doc);
 
     setPdfFile(file);
     setPdfBytes(arrayBuffer);
@@ -41,18 +74,197 @@ export default function App() {
     setOverlays([]);
     setSelectedOverlay(null);
     setDownloadUrl(null);
+    setMergeResults([]);
+    setBatchResults([]);
+    setPageLabels([]);
+    setFormData(null);
     setActiveTool(null);
     setPendingFile(null);
     setPendingBytes(null);
     setShowPasswordDialog(false);
     setPasswordInput("");
 
-    setStatus({
-      type: "success",
-      message: `Loaded: ${file.name} (${pages.length} page${pages.length > 1 ? "s" : ""})${password ? " 🔓" : ""}`,
-    });
+    const loaded = `Loaded: ${file.name} (${pages.length} page${pages.length > 1 ? "s" : ""})${password ? " 🔓" : ""}`;
+    setStatus(
+      info.signatures
+        ? {
+            type: "warning",
+            message: `${loaded}. This PDF is digitally signed; saving any changes will invalidate its signature${info.signatures > 1 ? "s" : ""}.`,
+          }
+        : { type: "success", message: loaded }
+    );
+  }, []);
+
+  // Editing rewrites the file, which breaks existing digital signatures
+  const confirmSignedEdit = useCallback(
+    () =>
+      !pdfInfo?.signatures ||
+      window.confirm(
+        `This PDF has ${pdfInfo.signatures} digital signature${pdfInfo.signatures > 1 ? "s" : ""}. ` +
+          "The generated copy will no longer be validly signed. Continue?"
+      ),
+    [pdfInfo]
+  );
+
+  // Swap in rewritten bytes for the open document, keeping everything the
+  // user has set up on it: elements, page labels, form data, the page
+  // being viewed and earlier outputs
+  const replaceDocument = useCallback((doc, bytes) => {
+    const info = documentInfo(doc);
+    setPdfFile((file) => new File([bytes], file.name, { type: "application/pdf" }));
+    setPdfBytes(bytes.buffer.slice(bytes.byteOffset, bytes.byteOffset + bytes.byteLength));
+    setPdfPassword(null); // pdf-lib saves without encryption
+    setPdfInfo(info);
+    setCurrentPage((page) => Math.min(page, info.pages));
   }, []);
 
+  // Page operations rewrite the working document, then swap it in.
+  // `transform` returns how much it changed (0 when nothing, and
+  // `unchanged` is reported instead); `afterReplace` gets that amount once
+  // the new document is in place. `reshapes` marks operations that change
+  // page boxes rather than move whole pages.
+  const handleTransformDocument = useCallback(
+    async (transform, describe, { unchanged, afterReplace, reshapes } = {}) => {
+      if (!confirmSignedEdit()) return;
+      setProcessing(true);
+      try {
+        const doc = await PDFDocument.load(pdfBytes, pdfPassword ? { password: pdfPassword } : {});
+        const changed = await transform(doc);
+        if (!changed) {
+          setStatus({ type: "info", message: unchanged });
+          return;
+        }
+        replaceDocument(doc, await doc.save());
+        afterReplace?.(changed);
+        // Positions are stored as percentages of the page box, so once the
+        // box changes, elements keep their place on the page, not on the content
+        setStatus(
+          reshapes && overlays.length
+            ? {
+                type: "warning",
+                message: `${describe(changed)}. Element positions are relative to the page, so check where they now sit on the content.`,
+              }
+            : { type: "success", message: describe(changed) }
+        );
+      } catch (err) {
+        setStatus({ type: "error", message: `Could not change the document: ${err.message}` });
+      } finally {
+        setProcessing(false);
+      }
+    },
+    [pdfBytes, pdfPassword, overlays, replaceDocument, confirmSignedEdit]
+  );
+
+  // Insert contents pages built from the bookmarks (to `depth` levels) or
+  // from typed entries; elements and page labels move with their pages
+  const handleInsertToc = useCallback(
+    async ({ title, before, depth, entriesText }) => {
+      const totalPages = pdfInfo?.pages || 0;
+      let entries;
+      try {
+        entries =
+          entriesText !== undefined
+            ? parseTocEntries(entriesText, totalPages)
+            : outlineEntries(await extractOutline(pdfBytes, pdfPassword), depth);
+      } catch (err) {
+        setStatus({ type: "error", message: `Table of contents failed: ${err.message}` });
+        return;
+      }
+      if (entries.length === 0) {
+        setStatus({ type: "info", message: "This PDF has no bookmarks that point to a page" });
+        return;
+      }
+      return handleTransformDocument(
+        (doc) => insertTableOfContents(doc, entries, { title, before }),
+        (n) =>
+          `Inserted ${n} contents page${n !== 1 ? "s" : ""} listing ${entries.length} entr${entries.length !== 1 ? "ies" : "y"}`,
+        {
+          afterReplace: (n) => {
+            const map = pageMapForInsert(before, n, totalPages);
+            setOverlays((prev) => renumberOverlays(prev, map, totalPages));
+            setPageLabels((prev) => renumberPageLabels(prev, map));
+          },
+        }
+      );
+    },
+    [pdfBytes, pdfPassword, pdfInfo, handleTransformDocument]
+  );
+
+  // Drop pages whose ink coverage is under `thresholdPercent`, moving
+  // elements and page label ranges along with the pages that remain
+  const handleRemoveBlankPages = useCallback(
+    async (thresholdPercent) => {
+      setStatus({ type: "info", message: "Looking for blank pages..." });
+      let ink;
+      try {
+        ink = await detectPageInk(pdfBytes, pdfPassword);
+      } catch (err) {
+        setStatus({ type: "error", message: `Blank page detection failed: ${err.message}` });
+        return;
+      }
+      let removed = [];
+      return handleTransformDocument(
+        (doc) => {
+          removed = removeBlankPages(doc, ink, thresholdPercent);
+          return removed.length;
+        },
+        (n) => `Removed ${n} blank page${n !== 1 ? "s" : ""} (${removed.join(", ")})`,
+        {
+          unchanged: "No blank pages found",
+          afterReplace: () => {
+            const map = pageMapForRemoval(removed, ink.length);
+            setOverlays((prev) => renumberOverlays(prev, map, ink.length));
+            setPageLabels((prev) => renumberPageLabels(prev, map));
+            setSelectedOverlay(null);
+          },
+        }
+      );
+    },
+    [pdfBytes, pdfPassword, handleTransformDocument]
+  );
+
+  const handleNormalizeOrientation = useCallback(
+    (options) =>
+      handleTransformDocument(
+        (doc) => normalizeOrientation(doc, options),
+        (n) => `Made ${n} page${n !== 1 ? "s" : ""} ${options.orientation}`,
+        { unchanged: "All pages already match; nothing changed", reshapes: true }
+      ),
+    [handleTransformDocument]
+  );
+
+  const handleAdjustMargins = useCallback(
+    (marginsMm) => {
+      const points = {};
+      for (const [edge, mm] of Object.entries(marginsMm)) points[edge] = mm * UNITS.mm.points;
+      return handleTransformDocument(
+        (doc) => adjustMargins(doc, points),
+        (n) => `Adjusted margins on ${n} page${n !== 1 ? "s" : ""}`,
+        { unchanged: "No margins to change", reshapes: true }
+      );
+    },
+    [handleTransformDocument]
+  );
+
+  const handleAutoCrop = useCallback(
+    async (paddingMm) => {
+      setStatus({ type: "info", message: "Finding page content..." });
+      let boxes;
+      try {
+        boxes = await detectContentBoxes(pdfBytes, pdfPassword);
+      } catch (err) {
+        setStatus({ type: "error", message: `Auto-crop failed: ${err.message}` });
+        return;
+      }
+      return handleTransformDocument(
+        (doc) => cropToContent(doc, boxes, paddingMm * UNITS.mm.points),
+        (n) => `Cropped ${n} page${n !== 1 ? "s" : ""} to their content`,
+        { unchanged: "No margins to crop", reshapes: true }
+      );
+    },
+    [pdfBytes, pdfPassword, handleTransformDocument]
+  );
+
   const handleUpload = useCallback(async (file) => {
     setStatus({ type: "info", message: "Loading PDF..." });
 
@@ -99,7 +311,7 @@ export default function App() {
 
       if (activeTool === "text") {
         const newOverlay = {
-          id: Date.now(),
+          id: newOverlayId(),
           type: "text",
           text: "Edit me",
           x: xPercent,
@@ -111,6 +323,35 @@ export default function App() {
         setOverlays((prev) => [...prev, newOverlay]);
         setSelectedOverlay(newOverlay.id);
         setActiveTool(null);
+      } else if (activeTool === "highlight") {
+        const newOverlay = {
+          id: newOverlayId(),
+          type: "highlight",
+          x: xPercent,
+          y: yPercent,
+          width: 30,
+          height: 3,
+          page: currentPage,
+          color: "#ffeb3b",
+          opacity: 0.4,
+        };
+        setOverlays((prev) => [...prev, newOverlay]);
+        setSelectedOverlay(newOverlay.id);
+        setActiveTool(null);
+      } else if (activeTool === "stamp") {
+        const newOverlay = {
+          id: newOverlayId(),
+          type: "stamp",
+          glyph: "check",
+          x: xPercent,
+          y: yPercent,
+          page: currentPage,
+          size: 16,
+          color: "#000000",
+        };
+        setOverlays((prev) => [...prev, newOverlay]);
+        setSelectedOverlay(newOverlay.id);
+        setActiveTool(null);
       }
     },
     [activeTool, currentPage]
@@ -119,7 +360,7 @@ export default function App() {
   const handleAddImage = useCallback(
     (imageData, fileName) => {
       const newOverlay = {
-        id: Date.now(),
+        id: newOverlayId(),
         type: "image",
         imageData,
         fileName: fileName || "Image",
@@ -136,6 +377,74 @@ export default function App() {
     [currentPage]
   );
 
+  // A Bates stamp is a text overlay on every page, bottom right by default
+  const handleAddBates = useCallback(() => {
+    const newOverlay = {
+      id: newOverlayId(),
+      type: "text",
+      text: "{bates}",
+      bates: { ...BATES_DEFAULTS },
+      x: 80,
+      y: 95,
+      page: currentPage,
+      pages: "all",
+      fontSize: 10,
+      color: "#000000",
+    };
+    setOverlays((prev) => [...prev, newOverlay]);
+    setSelectedOverlay(newOverlay.id);
+    setActiveTool(null);
+  }, [currentPage]);
+
+  const handleAddPdfStamp = useCallback(
+    async (pdfData, fileName) => {
+      let sourcePages;
+      try {
+        sourcePages = (await PDFDocument.load(pdfData)).getPageCount();
+      } catch (err) {
+        setStatus({ type: "error", message: `Cannot use ${fileName} as a stamp: ${err.message}` });
+        return;
+      }
+      const newOverlay = {
+        id: newOverlayId(),
+        type: "pdf",
+        pdfData,
+        fileName: fileName || "PDF",
+        sourcePage: 1,
+        sourcePages,
+        x: 0,
+        y: 0,
+        width: 100,
+        height: 100,
+        preserveAspect: true,
+        page: currentPage,
+      };
+      setOverlays((prev) => [...prev, newOverlay]);
+      setSelectedOverlay(newOverlay.id);
+      setActiveTool(null);
+    },
+    [currentPage]
+  );
+
+  // Templates carry their own page numbers; clamp them to this document
+  const handleApplyTemplate = useCallback(
+    (template) => {
+      const totalPages = pdfInfo?.pages || 1;
+      const added = template.overlays.map((o) => ({
+        ...o,
+        id: newOverlayId(),
+        page: Math.min(o.page || 1, totalPages),
+      }));
+      setOverlays((prev) => [...prev, ...added]);
+      setSelectedOverlay(null);
+      setStatus({
+        type: "success",
+        message: `Applied template "${template.name}" (${added.length} element${added.length !== 1 ? "s" : ""})`,
+      });
+    },
+    [pdfInfo]
+  );
+
   const handleUpdateOverlay = useCallback((id, updates) => {
     setOverlays((prev) =>
       prev.map((o) => (o.id === id ? { ...o, ...updates } : o))
@@ -150,17 +459,106 @@ export default function App() {
     [selectedOverlay]
   );
 
+  // Overlays are drawn in array order, so swapping with the neighbouring
+  // overlay on the same page moves one up or down the stacking order.
+  // Swap with the next element shown on the page being viewed, including
+  // ones that reach it through a page selection such as "all" or "1-3"
+  const handleReorderOverlay = useCallback(
+    (id, direction) => {
+      const totalPages = pdfInfo?.pages || 1;
+      setOverlays((prev) => {
+        const index = prev.findIndex((o) => o.id === id);
+        if (index < 0) return prev;
+        const step = direction === "up" ? 1 : -1;
+        let target = index + step;
+        while (
+          target >= 0 &&
+          target < prev.length &&
+          !isOnPage(prev[target], currentPage, totalPages)
+        ) {
+          target += step;
+        }
+        if (target < 0 || target >= prev.length) return prev;
+        const next = [...prev];
+        [next[index], next[target]] = [next[target], next[index]];
+        return next;
+      });
+    },
+    [currentPage, pdfInfo]
+  );
+
   const handleMoveOverlay = useCallback((id, x, y) => {
     setOverlays((prev) =>
       prev.map((o) => (o.id === id ? { ...o, x, y } : o))
     );
   }, []);
 
+  // Results describe the overlays as they were when checked
+  useEffect(() => setDiagnostics(null), [overlays]);
+
+  // Aborted by the Cancel button to stop the generation in progress
+  const abortRef = useRef(null);
+  const startCancellable = () => {
+    abortRef.current = new AbortController();
+    return { signal: abortRef.current.signal, timeoutMs: GENERATION_TIMEOUT_S * 1000 };
+  };
+  const handleCancel = useCallback(() => abortRef.current?.abort(), []);
+
+  // Select an overlay named in a diagnostic, turning to `page` (where the
+  // problem happened) or else to a page the overlay is on
+  const handleShowOverlay = useCallback(
+    (id, page) => {
+      const overlay = overlays.find((o) => o.id === id);
+      if (!overlay) return;
+      const totalPages = pdfInfo?.pages || 1;
+      if (page) {
+        setCurrentPage(page);
+      } else if (!isOnPage(overlay, currentPage, totalPages)) {
+        setCurrentPage(overlayPages(overlay, totalPages)[0] || currentPage);
+      }
+      setSelectedOverlay(id);
+    },
+    [overlays, pdfInfo, currentPage]
+  );
+
+  // Check overlays without generating; returns true when nothing would fail
+  const runValidation = useCallback(async (records) => {
+    const results = await validateOverlays(overlays, pdfInfo?.pages || 0, {
+      fileName: pdfFile?.name,
+      records,
+    });
+    setDiagnostics(results);
+    const errors = results.filter((d) => d.severity === "error").length;
+    const warnings = results.length - errors;
+    if (errors) {
+      setStatus({
+        type: "error",
+        message: `Found ${errors} problem${errors !== 1 ? "s" : ""} to fix before exporting`,
+      });
+    } else if (warnings) {
+      setStatus({
+        type: "warning",
+        message: `${warnings} warning${warnings !== 1 ? "s" : ""}; the PDF can still be generated`,
+      });
+    } else {
+      setStatus({ type: "success", message: "All elements look good" });
+    }
+    return errors === 0;
+  }, [overlays, pdfInfo, pdfFile]);
+
+  // Anything that makes the output differ from the original document
+  const hasEdits =
+    overlays.length > 0 || pageLabels.length > 0 || sanitize || scrub || !!formData;
+
   const handleProcess = useCallback(async () => {
-    if (!pdfBytes || overlays.length === 0) {
+    if (!pdfBytes || !hasEdits) {
       setStatus({ type: "error", message: "Add some text or images before generating" });
       return;
     }
+    if (!confirmSignedEdit()) return;
+    // When skipping failures, elements with problems are left out of the
+    // output rather than blocking it
+    if (!(await runValidation()) && !skipFailures) return;
 
     setProcessing(true);
     setStatus({ type: "info", message: "Generating PDF..." });
@@ -172,20 +570,354 @@ export default function App() {
     }
 
     try {
-      const resultBytes = await generatePDF(pdfBytes, overlays, pdfPassword);
+      let removed = [];
+      const skipped = [];
+      const resultBytes = await generatePDF(pdfBytes, overlays, pdfPassword, {
+        fileName: pdfFile?.name,
+        pageLabels,
+        imageQuality: IMAGE_QUALITY[imageQuality],
+        sanitize,
+        scrubMetadata: scrub,
+        formValues: formData?.values,
+        continueOnError: skipFailures,
+        onSkip: (err) => skipped.push(err),
+        ...startCancellable(),
+        onSanitize: (items) => (removed = items),
+        onProgress: ({ stage, done, total }) =>
+          setProgress({
+            label: stage === "saving" ? "Saving PDF" : `Applying element ${done + 1} of ${total}`,
+            done,
+            total,
+          }),
+      });
       const blob = new Blob([resultBytes], { type: "application/pdf" });
       const url = URL.createObjectURL(blob);
       setDownloadUrl(url);
-      setStatus({ type: "success", message: "PDF generated successfully!" });
+      let message = "PDF generated successfully!";
+      if (sanitize) {
+        message += removed.length
+          ? ` Removed: ${removed.join(", ")}.`
+          : " No active content was found.";
+      }
+      if (skipped.length) {
+        // List what was left out where the pre-flight results normally go
+        setDiagnostics(
+          skipped.map((err) => ({
+            index: err.details.index,
+            severity: "error",
+            code: err.code,
+            message: err.message,
+            page: err.details.page,
+          }))
+        );
+        message += ` Skipped ${skipped.length} element${skipped.length !== 1 ? "s" : ""} that couldn't be applied.`;
+      }
+      setStatus({ type: skipped.length ? "warning" : "success", message });
+    } catch (err) {
+      if (err.code === "CANCELLED") {
+        setStatus({ type: "info", message: "Generation cancelled" });
+        return;
+      }
+      setStatus({ type: "error", message: `Generation failed: ${describeError(err, overlays)}` });
+      // Point at the element that failed
+      if (err.details?.index != null) setSelectedOverlay(overlays[err.details.index]?.id ?? null);
+    } finally {
+      setProcessing(false);
+      setProgress(null);
+    }
+  }, [
+    pdfBytes,
+    overlays,
+    downloadUrl,
+    pdfPassword,
+    pdfFile,
+    pageLabels,
+    imageQuality,
+    sanitize,
+    scrub,
+    skipFailures,
+    formData,
+    hasEdits,
+    confirmSignedEdit,
+    runValidation,
+  ]);
+
+  // Download the document's current field values, with any imported
+  // values applied on top
+  const handleExportForm = useCallback(
+    async (format) => {
+      try {
+        const doc = await PDFDocument.load(pdfBytes, pdfPassword ? { password: pdfPassword } : {});
+        if (formData) fillForm(doc, formData.values);
+        const values = exportFormData(doc);
+        const blob =
+          format === "xfdf"
+            ? new Blob([toXfdf(values, pdfFile?.name)], { type: "application/vnd.adobe.xfdf" })
+            : new Blob([JSON.stringify(values, null, 2)], { type: "application/json" });
+        downloadBlob(blob, `${baseName(pdfFile)}.${format}`);
+      } catch (err) {
+        setStatus({ type: "error", message: `Export failed: ${err.message}` });
+      }
+    },
+    [pdfBytes, pdfPassword, pdfFile, formData]
+  );
+
+  const handleExportNavigation = useCallback(async () => {
+    try {
+      const navigation = await extractNavigation(pdfBytes, pdfPassword);
+      downloadBlob(
+        new Blob([JSON.stringify(navigation, null, 2)], { type: "application/json" }),
+        `${baseName(pdfFile)}-links.json`
+      );
+      setStatus({
+        type: "success",
+        message: `Exported ${navigation.links.length} links and ${navigation.outline.length} top-level bookmarks`,
+      });
+    } catch (err) {
+      setStatus({ type: "error", message: `Export failed: ${err.message}` });
+    }
+  }, [pdfBytes, pdfPassword, pdfFile]);
+
+  // Imported values are checked against the document's fields up front so
+  // mismatches are reported now rather than silently ignored later
+  const handleImportForm = useCallback(
+    async (file) => {
+      try {
+        const values = parseFormData(await file.text(), file.name);
+        const doc = await PDFDocument.load(pdfBytes, pdfPassword ? { password: pdfPassword } : {});
+        const skipped = fillForm(doc, values);
+        const applied = Object.keys(values).length - skipped.length;
+        setFormData({ name: file.name, values });
+        setStatus({
+          type: skipped.length ? "warning" : "success",
+          message:
+            `Loaded ${applied} field value${applied !== 1 ? "s" : ""} from ${file.name}` +
+            (skipped.length ? `; not applied: ${skipped.join(", ")}` : ""),
+        });
+      } catch (err) {
+        setStatus({ type: "error", message: `Could not read ${file.name}: ${err.message}` });
+      }
+    },
+    [pdfBytes, pdfPassword]
+  );
+
+  // Generate one PDF per record, expanding {field} placeholders from it
+  const handleMailMerge = useCallback(
+    async (records, nameField) => {
+      if (!pdfBytes || overlays.length === 0 || records.length === 0) return;
+      if (!confirmSignedEdit()) return;
+      // Every record's values have to be drawable in the chosen fonts
+      if (!(await runValidation(records))) return;
+
+      setProcessing(true);
+      mergeResults.forEach((r) => URL.revokeObjectURL(r.url));
+      setMergeResults([]);
+
+      const results = [];
+      const cancellable = startCancellable();
+      try {
+        for (let i = 0; i < records.length; i++) {
+          setProgress({ label: `Generating ${i + 1} of ${records.length}`, done: i, total: records.length });
+          const resultBytes = await generatePDF(pdfBytes, overlays, pdfPassword, {
+            fileName: pdfFile?.name,
+            variables: records[i],
+            pageLabels,
+            imageQuality: IMAGE_QUALITY[imageQuality],
+            sanitize,
+            scrubMetadata: scrub,
+            formValues: formData?.values,
+            ...cancellable,
+          });
+          const blob = new Blob([resultBytes], { type: "application/pdf" });
+          results.push({
+            name: mergeFileName(pdfFile?.name || "document.pdf", records[i], nameField, i),
+            url: URL.createObjectURL(blob),
+            bytes: resultBytes,
+          });
+        }
+        setStatus({ type: "success", message: `Generated ${results.length} PDFs` });
+      } catch (err) {
+        setStatus(
+          err.code === "CANCELLED"
+            ? { type: "info", message: `Mail merge cancelled after ${results.length} of ${records.length} records` }
+            : {
+                type: "error",
+                message: `Mail merge failed at record ${results.length + 1}: ${describeError(err, overlays)}`,
+              }
+        );
+      } finally {
+        setMergeResults(results);
+        setProcessing(false);
+        setProgress(null);
+      }
+    },
+    [
+      pdfBytes,
+      overlays,
+      pdfPassword,
+      pdfFile,
+      mergeResults,
+      pageLabels,
+      imageQuality,
+      sanitize,
+      scrub,
+      formData,
+      confirmSignedEdit,
+      runValidation,
+    ]
+  );
+
+  // Apply the current overlays to other documents; one bad file doesn't
+  // stop the rest, its error is reported in its place instead
+  const handleBatch = useCallback(
+    async (files) => {
+      if (overlays.length === 0 || files.length === 0) return;
+
+      // Every file is checked before any is processed, so signed ones can
+      // be confirmed once for the whole set
+      setProcessing(true);
+      const problems = [];
+      const signed = [];
+      for (const [i, file] of files.entries()) {
+        setProgress({ label: `Checking ${file.name}`, done: i, total: files.length });
+        problems[i] = await checkPdfFile(file);
+        if (!problems[i] && (await countFileSignatures(file))) signed.push(file.name);
+      }
+      setProgress(null);
+      if (signed.length && !confirmSignedBatch(signed)) {
+        setProcessing(false);
+        return;
+      }
+
+      batchResults.forEach((r) => r.url && URL.revokeObjectURL(r.url));
+      setBatchResults([]);
+
+      // Bates numbers run on across the whole set instead of restarting
+      const batesOverlays = overlays.filter((o) => o.bates);
+      let batesOffset = 0;
+
+      const results = [];
+      const cancellable = startCancellable();
+      let cancelled = false;
+      for (let i = 0; i < files.length; i++) {
+        const file = files[i];
+        setProgress({ label: `Processing ${file.name}`, done: i, total: files.length });
+        if (problems[i]) {
+          results.push({ name: file.name, error: problems[i] });
+          continue;
+        }
+        try {
+          let pageCount = 0;
+          const resultBytes = await generatePDF(await file.arrayBuffer(), overlays, null, {
+            fileName: file.name,
+            batesOffset,
+            onLoad: (info) => (pageCount = info.pageCount),
+            imageQuality: IMAGE_QUALITY[imageQuality],
+            sanitize,
+            scrubMetadata: scrub,
+            ...cancellable,
+          });
+          if (batesOverlays.length) {
+            batesOffset += Math.max(
+              ...batesOverlays.map((o) => overlayPages(o, pageCount).length)
+            );
+          }
+          const blob = new Blob([resultBytes], { type: "application/pdf" });
+          results.push({
+            name: editedFileName(file.name),
+            url: URL.createObjectURL(blob),
+            bytes: resultBytes,
+          });
+        } catch (err) {
+          if (err.code === "CANCELLED") {
+            cancelled = true;
+            break;
+          }
+          results.push({ name: file.name, error: describeError(err, overlays) });
+        }
+      }
+
+      const failed = results.filter((r) => r.error).length;
+      setStatus({
+        type: failed ? "error" : cancelled ? "info" : "success",
+        message: `Processed ${results.length - failed} of ${cancelled ? files.length : results.length} PDFs${failed ? ` (${failed} failed)` : ""}${cancelled ? "; cancelled" : ""}`,
+      });
+      setBatchResults(results);
+      setProcessing(false);
+      setProgress(null);
+    },
+    [overlays, batchResults, imageQuality, sanitize, scrub]
+  );
+
+  // Cut the working document into one PDF per top-level bookmark. Placed
+  // elements aren't applied; the parts are the pages as they are.
+  const handleSplitByBookmarks = useCallback(async () => {
+    setProcessing(true);
+    splitResults.forEach((r) => URL.revokeObjectURL(r.url));
+    setSplitResults([]);
+    try {
+      const outline = await extractOutline(pdfBytes, pdfPassword);
+      const ranges = bookmarkRanges(outline, pdfInfo?.pages || 0);
+      if (ranges.length === 0) {
+        setStatus({ type: "info", message: "This PDF has no top-level bookmarks that point to a page" });
+        return;
+      }
+      const parts = await splitDocument(pdfBytes, pdfPassword, ranges);
+      setSplitResults(
+        parts.map(({ name, bytes }) => ({
+          name,
+          bytes,
+          url: URL.createObjectURL(new Blob([bytes], { type: "application/pdf" })),
+        }))
+      );
+      setStatus({
+        type: "success",
+        message: `Split into ${parts.length} PDF${parts.length !== 1 ? "s" : ""} at top-level bookmarks`,
+      });
     } catch (err) {
-      setStatus({ type: "error", message: `Generation failed: ${err.message}` });
+      setStatus({ type: "error", message: `Split failed: ${err.message}` });
     } finally {
       setProcessing(false);
     }
-  }, [pdfBytes, overlays, downloadUrl, pdfPassword]);
+  }, [pdfBytes, pdfPassword, pdfInfo, splitResults]);
+
+  // Tile the current page across printer sheets as a separate PDF. Placed
+  // elements aren't applied; the poster is the page as it is.
+  const handlePoster = useCallback(
+    async (options) => {
+      setProcessing(true);
+      if (posterResult) URL.revokeObjectURL(posterResult.url);
+      setPosterResult(null);
+      try {
+        const { bytes, rows, columns } = await tilePage(pdfBytes, pdfPassword, currentPage, options);
+        setPosterResult({
+          name: `${baseName(pdfFile)}-poster-p${currentPage}.pdf`,
+          url: URL.createObjectURL(new Blob([bytes], { type: "application/pdf" })),
+        });
+        const sheets = rows * columns;
+        setStatus({
+          type: "success",
+          message: `Tiled page ${currentPage} across ${sheets} sheet${sheets !== 1 ? "s" : ""} (${rows} × ${columns})`,
+        });
+      } catch (err) {
+        setStatus({ type: "error", message: `Poster failed: ${err.message}` });
+      } finally {
+        setProcessing(false);
+      }
+    },
+    [pdfBytes, pdfPassword, pdfFile, currentPage, posterResult]
+  );
 
   const handleReset = useCallback(() => {
     if (downloadUrl) URL.revokeObjectURL(downloadUrl);
+    mergeResults.forEach((r) => URL.revokeObjectURL(r.url));
+    setMergeResults([]);
+    batchResults.forEach((r) => r.url && URL.revokeObjectURL(r.url));
+    setBatchResults([]);
+    splitResults.forEach((r) => URL.revokeObjectURL(r.url));
+    setSplitResults([]);
+    if (posterResult) URL.revokeObjectURL(posterResult.url);
+    setPosterResult(null);
     setPdfFile(null);
     setPdfBytes(null);
     setPdfPassword(null);
@@ -201,7 +933,7 @@ export default function App() {
     setStatus(null);
     setDownloadUrl(null);
     setProcessing(false);
-  }, [downloadUrl]);
+  }, [downloadUrl, mergeResults, batchResults, splitResults, posterResult]);
 
   return (
     <div className="app">
@@ -237,7 +969,10 @@ export default function App() {
       )}
 
       {!pdfFile && !showPasswordDialog ? (
-        <PDFUploader onUpload={handleUpload} />
+        <PDFUploader
+          onUpload={handleUpload}
+          onError={(message) => setStatus({ type: "error", message })}
+        />
       ) : pdfFile ? (
         <div className="editor-layout">
             <div className="editor-sidebar">
@@ -246,15 +981,61 @@ export default function App() {
                 setActiveTool={setActiveTool}
                 overlays={overlays}
                 currentPage={currentPage}
+                totalPages={pdfInfo?.pages || 1}
+                pageSizes={pdfInfo?.pageWidths.map((width, i) => ({
+                  width,
+                  height: pdfInfo.pageHeights[i],
+                }))}
                 selectedOverlay={selectedOverlay}
                 setSelectedOverlay={setSelectedOverlay}
                 onUpdateOverlay={handleUpdateOverlay}
                 onDeleteOverlay={handleDeleteOverlay}
+                onReorderOverlay={handleReorderOverlay}
                 onAddImage={handleAddImage}
+                onAddPdfStamp={handleAddPdfStamp}
+                onAddBates={handleAddBates}
+                onApplyTemplate={handleApplyTemplate}
                 onProcess={handleProcess}
+                onMailMerge={handleMailMerge}
+                mergeResults={mergeResults}
+                imageQuality={imageQuality}
+                sanitize={sanitize}
+                onSanitizeChange={setSanitize}
+                scrub={scrub}
+                onScrubChange={setScrub}
+                skipFailures={skipFailures}
+                onSkipFailuresChange={setSkipFailures}
+                hasEdits={hasEdits}
+                formFieldCount={pdfInfo?.formFields || 0}
+                formData={formData}
+                onExportForm={handleExportForm}
+                onImportForm={handleImportForm}
+                onClearForm={() => setFormData(null)}
+                onExportNavigation={handleExportNavigation}
+                onValidate={() => runValidation()}
+                diagnostics={diagnostics}
+                onShowOverlay={handleShowOverlay}
+                onNormalizeOrientation={handleNormalizeOrientation}
+                onAdjustMargins={handleAdjustMargins}
+                onAutoCrop={handleAutoCrop}
+                onImageQualityChange={setImageQuality}
+                pageLabels={pageLabels}
+                onPageLabelsChange={setPageLabels}
+                onInsertToc={handleInsertToc}
+                onPoster={handlePoster}
+                posterResult={posterResult}
+                onRemoveBlankPages={handleRemoveBlankPages}
+                onBatch={handleBatch}
+                batchResults={batchResults}
+                onSplitByBookmarks={handleSplitByBookmarks}
+                splitResults={splitResults}
+                splitZipName={`${baseName(pdfFile)}-parts.zip`}
                 onReset={handleReset}
+                onCancel={handleCancel}
                 processing={processing}
+                progress={progress}
                 downloadUrl={downloadUrl}
+                downloadName={editedFileName(pdfFile.name)}
               />
             </div>
             <div className="editor-main">
@@ -263,7 +1044,7 @@ export default function App() {
                 currentPage={currentPage}
                 setCurrentPage={setCurrentPage}
                 totalPages={pdfInfo?.pages || 1}
-                overlays={overlays.filter((o) => o.page === currentPage)}
+                overlays={overlays.filter((o) => isOnPage(o, currentPage, pdfInfo?.pages || 1))}
                 selectedOverlay={selectedOverlay}
                 setSelectedOverlay={setSelectedOverlay}
                 onCanvasClick={handleCanvasClick}
@@ -279,3 +1060,36 @@ export default function App() {
     </div>
   );
 }
+
+// Download name stem for files derived from the open document
+function baseName(pdfFile) {
+  return (pdfFile?.name || "document.pdf").replace(/\.pdf$/i, "");
+}
+
+// Page count and sizes the editor lays elements out against
+function documentInfo(doc) {
+  const pages = doc.getPages();
+  return {
+    pages: pages.length,
+    pageWidths: pages.map((p) => p.getCropBox().width),
+    pageHeights: pages.map((p) => p.getCropBox().height),
+    signatures: countDigitalSignatures(doc),
+    formFields: doc.getForm().getFields().length,
+  };
+}
+
+// One confirmation for every signed file in a batch, naming them
+function confirmSignedBatch(names) {
+  const listed = names.slice(0, 10).join(", ") + (names.length > 10 ? `, and ${names.length - 10} more` : "");
+  return window.confirm(
+    `${names.length} of these PDFs ${names.length > 1 ? "are" : "is"} digitally signed: ${listed}. ` +
+      "Their processed copies will no longer be validly signed. Continue?"
+  );
+}
+
+// Overlay ids only need to be unique within the session. Timestamps
+// aren't: overlays created in the same millisecond would share one.
+let lastOverlayId = 0;
+function newOverlayId() {
+  return ++lastOverlayId;
+}
@@ -0,0 +1,13 @@
+/**
+ * Bates numbering for legal productions: a fixed prefix followed by a
+ * zero-padded counter that increases by one on every stamped page.
+ */
+export const BATES_DEFAULTS = { prefix: "", start: 1, digits: 6 };
+
+/**
+ * The Bates number for the nth (0-based) stamped page of a sequence.
+ */
+export function formatBates(bates, n) {
+  const { prefix, start, digits } = { ...BATES_DEFAULTS, ...bates };
+  return `${prefix}${String(start + n).padStart(digits, "0")}`;
+}
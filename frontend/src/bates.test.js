@@ -0,0 +1,25 @@
+import { test } from "node:test";
+import assert from "node:assert/strict";
+import { formatBates } from "./bates.js";
+
+test("pads the counter and counts up from the start number", () => {
+  const bates = { prefix: "ABC", start: 1, digits: 6 };
+  assert.equal(formatBates(bates, 0), "ABC000001");
+  assert.equal(formatBates(bates, 41), "ABC000042");
+});
+
+test("fills in defaults for missing settings", () => {
+  assert.equal(formatBates({}, 0), "000001");
+  assert.equal(formatBates(undefined, 2), "000003");
+  assert.equal(formatBates({ prefix: "X-" }, 9), "X-000010");
+});
+
+test("starts from zero and handles a zero-width counter", () => {
+  assert.equal(formatBates({ start: 0, digits: 3 }, 0), "000");
+  assert.equal(formatBates({ start: 7, digits: 0 }, 0), "7");
+});
+
+test("keeps every digit once the counter outgrows its padding", () => {
+  assert.equal(formatBates({ prefix: "P", start: 999, digits: 3 }, 0), "P999");
+  assert.equal(formatBates({ prefix: "P", start: 999, digits: 3 }, 1), "P1000");
+});
@@ -0,0 +1,48 @@
+import { test } from "node:test";
+import assert from "node:assert/strict";
+import { parseColor, toHexColor } from "./colors.js";
+
+test("parses every hex length", () => {
+  assert.deepEqual(parseColor("#f00"), { r: 1, g: 0, b: 0, alpha: 1 });
+  assert.deepEqual(parseColor("#F00F"), { r: 1, g: 0, b: 0, alpha: 1 });
+  assert.deepEqual(parseColor("#ff0000"), { r: 1, g: 0, b: 0, alpha: 1 });
+  assert.deepEqual(parseColor("#ff000000"), { r: 1, g: 0, b: 0, alpha: 0 });
+});
+
+test("parses rgb() and rgba() in comma and space syntax", () => {
+  assert.deepEqual(parseColor("rgb(255, 0, 0)"), { r: 1, g: 0, b: 0, alpha: 1 });
+  assert.deepEqual(parseColor("RGBA(0,0,255,0.5)"), { r: 0, g: 0, b: 1, alpha: 0.5 });
+  assert.deepEqual(parseColor("rgb(100% 0% 0% / 25%)"), { r: 1, g: 0, b: 0, alpha: 0.25 });
+});
+
+test("parses named colors, ignoring case and surrounding spaces", () => {
+  assert.deepEqual(parseColor("  White "), { r: 1, g: 1, b: 1, alpha: 1 });
+  assert.deepEqual(parseColor("transparent"), { r: 0, g: 0, b: 0, alpha: 0 });
+});
+
+test("rejects malformed colors", () => {
+  const malformed = [
+    "", "#", "#ff", "#fffff", "#ggg", "#ff00000", "notacolor",
+    "rgb()", "rgb(1, 2)", "rgb(1, 2, 3, 4, 5)", "rgb(a, b, c)", "rgb(255, 0, 0",
+  ];
+  for (const value of malformed) {
+    assert.equal(parseColor(value), null, value);
+  }
+  assert.equal(parseColor(undefined), null);
+  assert.equal(parseColor(0xff0000), null);
+});
+
+test("rejects out-of-range components instead of clamping", () => {
+  assert.equal(parseColor("rgb(256, 0, 0)"), null);
+  assert.equal(parseColor("rgb(-1, 0, 0)"), null);
+  assert.equal(parseColor("rgb(101%, 0%, 0%)"), null);
+  assert.equal(parseColor("rgba(0, 0, 0, 1.5)"), null);
+  assert.deepEqual(parseColor("rgba(0, 0, 0, 1)"), { r: 0, g: 0, b: 0, alpha: 1 });
+});
+
+test("converts to #rrggbb for color inputs", () => {
+  assert.equal(toHexColor("red", "#000000"), "#ff0000");
+  assert.equal(toHexColor("#abc8", "#000000"), "#aabbcc");
+  assert.equal(toHexColor("rgb(0 128 255 / 0.5)", "#000000"), "#0080ff");
+  assert.equal(toHexColor("bogus", "#123456"), "#123456");
+});
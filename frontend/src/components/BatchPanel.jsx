@@ -0,0 +1,34 @@
+import React, { useRef } from "react";
+import OutputList from "./OutputList";
+
+export default function BatchPanel({ onBatch, batchResults, processing, disabled }) {
+  const inputRef = useRef();
+
+  return (
+    <div className="panel">
+      <h3>Batch</h3>
+      <p style={{ fontSize: 12, color: "#888", marginBottom: 8 }}>
+        Apply the current elements to other PDFs with the same layout.
+      </p>
+      <button
+        className="btn btn-sm"
+        disabled={processing || disabled}
+        onClick={() => inputRef.current?.click()}
+      >
+        📚 Choose PDFs...
+      </button>
+      <input
+        ref={inputRef}
+        type="file"
+        accept=".pdf,application/pdf"
+        multiple
+        style={{ display: "none" }}
+        onChange={(e) => {
+          onBatch([...e.target.files]);
+          e.target.value = "";
+        }}
+      />
+      <OutputList results={batchResults} zipName="batch.zip" />
+    </div>
+  );
+}
@@ -0,0 +1,31 @@
+import React, { useEffect, useState } from "react";
+import { parseColor, toHexColor } from "../colors";
+
+/**
+ * A color picker with a text field beside it for typed colors such as
+ * "navy" or "rgba(0, 0, 0, 0.5)". Typed text is only applied once it
+ * parses, so a half-typed value never reaches the overlay.
+ */
+export default function ColorInput({ value, fallback, onChange }) {
+  const [text, setText] = useState(value || fallback);
+  useEffect(() => setText(value || fallback), [value, fallback]);
+
+  return (
+    <div className="color-input">
+      <input
+        type="color"
+        value={toHexColor(value, fallback)}
+        onChange={(e) => onChange(e.target.value)}
+      />
+      <input
+        type="text"
+        value={text}
+        className={parseColor(text) ? "" : "invalid"}
+        onChange={(e) => {
+          setText(e.target.value);
+          if (parseColor(e.target.value)) onChange(e.target.value);
+        }}
+      />
+    </div>
+  );
+}
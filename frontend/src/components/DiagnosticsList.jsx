@@ -0,0 +1,36 @@
+import React from "react";
+import { overlayReference } from "../overlayLabels";
+
+/**
+ * Results of checking overlays before export. Each entry names the page
+ * and element it refers to; clicking it turns to that page and selects
+ * the element.
+ */
+export default function DiagnosticsList({ diagnostics, overlays, onSelect }) {
+  if (!diagnostics) return null;
+  if (diagnostics.length === 0) {
+    return <p style={{ fontSize: 12, color: "#219a52" }}>✅ No problems found</p>;
+  }
+
+  return (
+    <div className="overlay-list">
+      {diagnostics.map((d, i) => {
+        const overlay = overlays[d.index];
+        return (
+          <div
+            key={i}
+            className={`overlay-item diagnostic ${d.severity}`}
+            onClick={() => overlay && onSelect(overlay.id, d.page)}
+            title={d.code}
+          >
+            <span>
+              {d.severity === "error" ? "⛔" : "⚠️"}{" "}
+              {overlay ? `${overlayReference(overlay, d.page)}: ` : ""}
+              {d.message}
+            </span>
+          </div>
+        );
+      })}
+    </div>
+  );
+}
@@ -0,0 +1,46 @@
+import React, { useRef } from "react";
+
+export default function FormDataPanel({ fieldCount, formData, onExport, onImport, onClear }) {
+  const inputRef = useRef();
+
+  return (
+    <div className="panel">
+      <h3>Form Data</h3>
+      <p style={{ fontSize: 12, color: "#888", marginBottom: 8 }}>
+        This PDF has {fieldCount} form field{fieldCount !== 1 ? "s" : ""}. Export their
+        values, or import JSON / XFDF to fill them in the generated PDF.
+      </p>
+      <div className="toolbar" style={{ marginBottom: 0 }}>
+        <button className="btn btn-sm" onClick={() => onExport("json")}>
+          ⬇️ JSON
+        </button>
+        <button className="btn btn-sm" onClick={() => onExport("xfdf")}>
+          ⬇️ XFDF
+        </button>
+        <button className="btn btn-sm" onClick={() => inputRef.current?.click()}>
+          📥 Import...
+        </button>
+      </div>
+      <input
+        ref={inputRef}
+        type="file"
+        accept=".json,.xfdf,.xml,application/json,application/vnd.adobe.xfdf"
+        style={{ display: "none" }}
+        onChange={(e) => {
+          if (e.target.files[0]) onImport(e.target.files[0]);
+          e.target.value = "";
+        }}
+      />
+      {formData && (
+        <div className="overlay-item" style={{ marginTop: 8 }}>
+          <span>
+            {formData.name} ({Object.keys(formData.values).length})
+          </span>
+          <button className="btn btn-danger btn-sm" onClick={onClear}>
+            ✕
+          </button>
+        </div>
+      )}
+    </div>
+  );
+}
@@ -1,33 +1,86 @@
-import React, { useRef, useCallback } from "react";
+import React, { useRef, useCallback, useState } from "react";
+import { MAX_OVERLAY_SIZE_MB } from "../config";
+import { fetchFile } from "../fetchFile";
+
+// Formats some browsers can't decode, or can display but not re-encode
+// reliably, so they're converted to PNG as soon as they're picked
+const CONVERTED_TYPES = /^image\/(heic|heif|avif)$/;
+const CONVERTED_EXTENSIONS = /\.(heic|heif|avif)$/i;
+
+function toPngDataUrl(dataUrl) {
+  return new Promise((resolve, reject) => {
+    const img = new Image();
+    img.onload = () => {
+      const canvas = document.createElement("canvas");
+      canvas.width = img.naturalWidth;
+      canvas.height = img.naturalHeight;
+      canvas.getContext("2d").drawImage(img, 0, 0);
+      resolve(canvas.toDataURL("image/png"));
+    };
+    img.onerror = () => reject(new Error("decode failed"));
+    img.src = dataUrl;
+  });
+}
 
 export default function ImageUploader({ onUpload, onCancel }) {
   const inputRef = useRef();
+  const [url, setUrl] = useState("");
+  const [fetching, setFetching] = useState(false);
 
   const handleFile = useCallback(
     (file) => {
       if (!file) return;
 
-      // Validate it's an image
-      if (!file.type.startsWith("image/")) {
-        alert("Please select an image file (PNG, JPG, GIF, WebP)");
+      // Validate it's an image; HEIC files often arrive without a MIME type
+      const convert = CONVERTED_TYPES.test(file.type) || CONVERTED_EXTENSIONS.test(file.name);
+      if (!file.type.startsWith("image/") && !convert) {
+        alert("Please select an image file (PNG, JPG, GIF, WebP, AVIF, HEIC)");
         return;
       }
 
-      // Max 10MB
-      if (file.size > 10 * 1024 * 1024) {
-        alert("Image must be under 10MB");
+      if (file.size > MAX_OVERLAY_SIZE_MB * 1024 * 1024) {
+        alert(`Image must be under ${MAX_OVERLAY_SIZE_MB}MB`);
         return;
       }
 
       const reader = new FileReader();
-      reader.onload = (e) => {
-        onUpload(e.target.result, file.name);
+      reader.onload = async (e) => {
+        if (!convert) {
+          onUpload(e.target.result, file.name);
+          return;
+        }
+        try {
+          onUpload(await toPngDataUrl(e.target.result), file.name);
+        } catch (err) {
+          alert(
+            `This browser can't open ${file.name}. Save it as JPEG or PNG first ` +
+              "(on iPhone: Settings → Camera → Formats → Most Compatible)."
+          );
+        }
       };
       reader.readAsDataURL(file);
     },
     [onUpload]
   );
 
+  const handleUrl = async (e) => {
+    e.preventDefault();
+    setFetching(true);
+    try {
+      handleFile(
+        await fetchFile(url, {
+          maxSizeMB: MAX_OVERLAY_SIZE_MB,
+          fallbackName: "image.png",
+          fallbackType: "",
+        })
+      );
+    } catch (err) {
+      alert(err.message);
+    } finally {
+      setFetching(false);
+    }
+  };
+
   const handleDrop = useCallback(
     (e) => {
       e.preventDefault();
@@ -68,16 +121,35 @@ export default function ImageUploader({ onUpload, onCancel }) {
           Click or drag an image here
         </p>
         <p style={{ fontSize: 11, color: "#aaa", margin: "4px 0 0" }}>
-          PNG, JPG, GIF, WebP — max 10MB
+          PNG, JPG, GIF, WebP, AVIF, HEIC — max {MAX_OVERLAY_SIZE_MB}MB
         </p>
         <input
           ref={inputRef}
           type="file"
-          accept="image/*"
+          accept="image/*,.heic,.heif,.avif"
           style={{ display: "none" }}
           onChange={(e) => handleFile(e.target.files[0])}
         />
       </div>
+      <form className="form-row" onSubmit={handleUrl}>
+        <div className="form-group">
+          <input
+            type="url"
+            placeholder="…or an image link (https://)"
+            value={url}
+            onChange={(e) => setUrl(e.target.value)}
+            disabled={fetching}
+          />
+        </div>
+        <button
+          type="submit"
+          className="btn btn-sm"
+          style={{ height: 34 }}
+          disabled={fetching || !url.trim()}
+        >
+          {fetching ? "..." : "Add"}
+        </button>
+      </form>
       <div style={{ display: "flex", justifyContent: "flex-end" }}>
         <button className="btn btn-sm" onClick={onCancel}>
           Cancel
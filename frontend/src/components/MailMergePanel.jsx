@@ -0,0 +1,88 @@
+import React, { useRef, useState } from "react";
+import { parseRecords, placeholderFields } from "../mailMerge";
+import OutputList from "./OutputList";
+
+export default function MailMergePanel({ onMailMerge, mergeResults, processing, disabled }) {
+  const inputRef = useRef();
+  const [records, setRecords] = useState([]);
+  const [dataName, setDataName] = useState(null);
+  const [nameField, setNameField] = useState("");
+  const [error, setError] = useState(null);
+
+  const fields = records.length > 0 ? Object.keys(records[0]) : [];
+  const { usable, unusable } = placeholderFields(records[0]);
+
+  const handleFile = async (file) => {
+    if (!file) return;
+    try {
+      const parsed = parseRecords(await file.text(), file.name);
+      setRecords(parsed);
+      setDataName(file.name);
+      setNameField("");
+      setError(parsed.length === 0 ? "No records found" : null);
+    } catch (e) {
+      setRecords([]);
+      setError(`Could not read ${file.name}: ${e.message}`);
+    }
+  };
+
+  return (
+    <div className="panel">
+      <h3>Mail Merge</h3>
+      <p style={{ fontSize: 12, color: "#888", marginBottom: 8 }}>
+        Use {"{field}"} placeholders in text, then load a CSV or JSON data set
+        to generate one PDF per record.
+      </p>
+      <button className="btn btn-sm" onClick={() => inputRef.current?.click()}>
+        📊 {dataName ? `${dataName} (${records.length})` : "Load data"}
+      </button>
+      <input
+        ref={inputRef}
+        type="file"
+        accept=".csv,.json,text/csv,application/json"
+        style={{ display: "none" }}
+        onChange={(e) => handleFile(e.target.files[0])}
+      />
+      {error && <p style={{ fontSize: 12, color: "#c0392b", marginTop: 4 }}>{error}</p>}
+
+      {records.length > 0 && (
+        <>
+          <p style={{ fontSize: 11, color: "#aaa", margin: "6px 0" }}>
+            Fields: {usable.map((f) => `{${f}}`).join(" ")}
+          </p>
+          {unusable.length > 0 && (
+            <p style={{ fontSize: 11, color: "#c0392b", margin: "6px 0" }}>
+              Can't be used as placeholders:{" "}
+              {unusable.map((f) => (f ? `"${f}"` : "(empty header)")).join(", ")}
+            </p>
+          )}
+          <div className="form-group">
+            <label>Name files by</label>
+            <select value={nameField} onChange={(e) => setNameField(e.target.value)}>
+              <option value="">Record number</option>
+              {fields.map((f) => (
+                <option key={f} value={f}>
+                  {f}
+                </option>
+              ))}
+            </select>
+          </div>
+          <button
+            className="btn btn-primary"
+            style={{ width: "100%" }}
+            disabled={processing || disabled}
+            onClick={() => onMailMerge(records, nameField)}
+          >
+            Generate {records.length} PDFs
+          </button>
+        </>
+      )}
+
+      <OutputList
+        results={mergeResults}
+        zipName={`${(dataName || "merge").replace(/\.\w+$/, "")}.zip`}
+      />
+    </div>
+  );
+}
+
@@ -0,0 +1,45 @@
+import React from "react";
+import { createZip } from "../zip";
+import { downloadBlob } from "../download";
+
+/**
+ * Download links for a set of generated PDFs, plus a ZIP of all successful
+ * ones. Failed items ({ name, error }) are listed with their error.
+ */
+export default function OutputList({ results, zipName }) {
+  const succeeded = results.filter((r) => !r.error);
+  if (results.length === 0) return null;
+
+  return (
+    <>
+      {succeeded.length > 0 && (
+        <button
+          className="btn btn-success"
+          style={{ width: "100%", marginTop: 8 }}
+          onClick={() => downloadZip(succeeded, zipName)}
+        >
+          🗜️ Download all as ZIP ({succeeded.length})
+        </button>
+      )}
+      <div className="overlay-list" style={{ marginTop: 8 }}>
+        {results.map((r, i) => (
+          <div key={r.url || `${r.name}-${i}`} className="overlay-item">
+            {r.error ? (
+              <span style={{ color: "#c0392b" }} title={r.error}>
+                ⚠️ {r.name}: {r.error}
+              </span>
+            ) : (
+              <a href={r.url} download={r.name}>
+                ⬇️ {r.name}
+              </a>
+            )}
+          </div>
+        ))}
+      </div>
+    </>
+  );
+}
+
+function downloadZip(results, zipName) {
+  downloadBlob(createZip(results.map((r) => ({ name: r.name, data: r.bytes }))), zipName);
+}
@@ -0,0 +1,73 @@
+import React, { useRef, useCallback } from "react";
+import { MAX_OVERLAY_SIZE_MB } from "../config";
+import { sniffFileType } from "../fileType";
+
+export default function PDFStampUploader({ onUpload, onCancel }) {
+  const inputRef = useRef();
+
+  const handleFile = useCallback(
+    async (file) => {
+      if (!file) return;
+
+      if ((await sniffFileType(file)) !== "pdf") {
+        alert("Please select a PDF file to use as a stamp");
+        return;
+      }
+
+      if (file.size > MAX_OVERLAY_SIZE_MB * 1024 * 1024) {
+        alert(`Stamp PDF must be under ${MAX_OVERLAY_SIZE_MB}MB`);
+        return;
+      }
+
+      const reader = new FileReader();
+      reader.onload = (e) => {
+        onUpload(e.target.result, file.name);
+      };
+      reader.readAsDataURL(file);
+    },
+    [onUpload]
+  );
+
+  return (
+    <div style={{ marginTop: 8 }}>
+      <div
+        style={{
+          border: "2px dashed #ccc",
+          borderRadius: 8,
+          padding: "20px 12px",
+          textAlign: "center",
+          cursor: "pointer",
+          background: "#fafafa",
+          marginBottom: 8,
+        }}
+        onClick={() => inputRef.current?.click()}
+        onDragOver={(e) => e.preventDefault()}
+        onDrop={(e) => {
+          e.preventDefault();
+          e.stopPropagation();
+          handleFile(e.dataTransfer.files[0]);
+        }}
+      >
+        <div style={{ fontSize: 24, marginBottom: 4 }}>📄</div>
+        <p style={{ fontSize: 12, color: "#666", margin: 0 }}>
+          Click or drag a letterhead or form PDF here
+        </p>
+        <p style={{ fontSize: 11, color: "#aaa", margin: "4px 0 0" }}>
+          Stays vector in the output — max {MAX_OVERLAY_SIZE_MB}MB
+        </p>
+        <input
+          ref={inputRef}
+          type="file"
+          accept=".pdf,application/pdf"
+          style={{ display: "none" }}
+          onChange={(e) => handleFile(e.target.files[0])}
+        />
+      </div>
+      <div style={{ display: "flex", justifyContent: "flex-end" }}>
+        <button className="btn btn-sm" onClick={onCancel}>
+          Cancel
+        </button>
+      </div>
+    </div>
+  );
+}
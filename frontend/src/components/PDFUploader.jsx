@@ -1,16 +1,48 @@
-import React, { useRef, useCallback } from "react";
+import React, { useRef, useCallback, useState } from "react";
+import { MAX_PDF_SIZE_MB } from "../config";
+import { checkPdfFile } from "../fileType";
+import { fetchFile } from "../fetchFile";
+import TextToPdfForm from "./TextToPdfForm";
 
-export default function PDFUploader({ onUpload }) {
+export default function PDFUploader({ onUpload, onError }) {
   const inputRef = useRef();
   const [dragging, setDragging] = React.useState(false);
+  const [url, setUrl] = useState("");
+  const [fetching, setFetching] = useState(false);
 
   const handleFile = useCallback(
-    (file) => {
-      if (file && file.type === "application/pdf") {
-        onUpload(file);
+    async (file) => {
+      if (!file) return;
+      const problem = await checkPdfFile(file);
+      if (problem) {
+        onError?.(problem);
+        return;
       }
+      onUpload(file);
     },
-    [onUpload]
+    [onUpload, onError]
+  );
+
+  // Downloaded documents go through the same checks as a local file
+  const handleUrl = useCallback(
+    async (e) => {
+      e.preventDefault();
+      setFetching(true);
+      try {
+        const file = await fetchFile(url, {
+          maxSizeMB: MAX_PDF_SIZE_MB,
+          fallbackName: "document.pdf",
+          fallbackType: "application/pdf",
+        });
+        await handleFile(file);
+        setUrl("");
+      } catch (err) {
+        onError?.(err.message);
+      } finally {
+        setFetching(false);
+      }
+    },
+    [url, handleFile, onError]
   );
 
   const handleDrop = useCallback(
@@ -24,24 +56,42 @@ export default function PDFUploader({ onUpload }) {
   );
 
   return (
-    <div
-      className={`upload-area ${dragging ? "dragging" : ""}`}
-      onClick={() => inputRef.current?.click()}
-      onDragOver={(e) => {
-        e.preventDefault();
-        setDragging(true);
-      }}
-      onDragLeave={() => setDragging(false)}
-      onDrop={handleDrop}
-    >
-      <h3>📄 Upload a PDF</h3>
-      <p>Click to browse or drag and drop a PDF file here</p>
-      <input
-        ref={inputRef}
-        type="file"
-        accept=".pdf"
-        onChange={(e) => handleFile(e.target.files[0])}
-      />
-    </div>
+    <>
+      <div
+        className={`upload-area ${dragging ? "dragging" : ""}`}
+        onClick={() => inputRef.current?.click()}
+        onDragOver={(e) => {
+          e.preventDefault();
+          setDragging(true);
+        }}
+        onDragLeave={() => setDragging(false)}
+        onDrop={handleDrop}
+      >
+        <h3>📄 Upload a PDF</h3>
+        <p>Click to browse or drag and drop a PDF file here (max {MAX_PDF_SIZE_MB}MB)</p>
+        <input
+          ref={inputRef}
+          type="file"
+          accept=".pdf,application/pdf"
+          onChange={(e) => handleFile(e.target.files[0])}
+        />
+      </div>
+      <form className="upload-url" onSubmit={handleUrl}>
+        <input
+          type="url"
+          placeholder="…or paste a link to a PDF (https://)"
+          value={url}
+          onChange={(e) => setUrl(e.target.value)}
+          disabled={fetching}
+        />
+        <button type="submit" className="btn" disabled={fetching || !url.trim()}>
+          {fetching ? "Downloading..." : "Open"}
+        </button>
+      </form>
+      <details className="upload-text">
+        <summary>…or create a PDF from text</summary>
+        <TextToPdfForm onCreate={onUpload} onError={onError} />
+      </details>
+    </>
   );
 }
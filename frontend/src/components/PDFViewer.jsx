@@ -1,5 +1,7 @@
 import React, { useRef, useEffect, useState, useCallback } from "react";
 import * as pdfjsLib from "pdfjs-dist";
+import { STAMP_GLYPHS, STAMP_STROKE } from "../stamps";
+import { FONT_FAMILIES } from "../fonts";
 
 pdfjsLib.GlobalWorkerOptions.workerSrc = new URL(
   "pdfjs-dist/build/pdf.worker.mjs",
@@ -201,6 +203,32 @@ export default function PDFViewer({
   );
 }
 
+const VERTICAL_ALIGN = { top: "flex-start", middle: "center", bottom: "flex-end" };
+
+// The preview can't draw under the rendered page, so elements placed behind
+// the content are multiplied to approximate how they'll look on white paper
+function blendPreview(overlay) {
+  return overlay.multiply || overlay.background ? "multiply" : undefined;
+}
+
+// Outline and background box for text overlays, matching the generator:
+// the stroke sits under the fill, and padding grows the box outward
+// without moving the text
+function textDecorationStyle(overlay) {
+  const style = {};
+  if (overlay.strokeWidth > 0) {
+    style.WebkitTextStroke = `${overlay.strokeWidth * 2}px ${overlay.strokeColor || "#ffffff"}`;
+    style.paintOrder = "stroke fill";
+  }
+  if (overlay.backgroundColor) {
+    const padding = overlay.backgroundPadding ?? 4;
+    style.background = overlay.backgroundColor;
+    style.boxShadow = `0 0 0 ${padding}px ${overlay.backgroundColor}`;
+    style.borderRadius = `${Math.max((overlay.backgroundRadius || 0) - padding, 0)}px`;
+  }
+  return style;
+}
+
 function OverlayElement({
   overlay,
   isSelected,
@@ -210,9 +238,12 @@ function OverlayElement({
   containerHeight,
   onUpdateOverlay,
 }) {
+  // Rotation is counterclockwise (as in PDF) around the top-left corner
   const style = {
     left: `${overlay.x}%`,
     top: `${overlay.y}%`,
+    transform: overlay.rotation ? `rotate(${-overlay.rotation}deg)` : undefined,
+    transformOrigin: "top left",
   };
 
   const [editing, setEditing] = React.useState(false);
@@ -232,6 +263,14 @@ function OverlayElement({
     }
   }, [editing]);
 
+  // Grow the inline editor with its content, wrapped lines included
+  React.useLayoutEffect(() => {
+    const el = inputRef.current;
+    if (!editing || !el) return;
+    el.style.height = "auto";
+    el.style.height = `${el.scrollHeight}px`;
+  }, [editing, editValue]);
+
   if (overlay.type === "text") {
     return (
       <div
@@ -240,6 +279,14 @@ function OverlayElement({
           ...style,
           fontSize: `${overlay.fontSize}px`,
           color: overlay.color || "#000000",
+          fontFamily: (FONT_FAMILIES[overlay.fontFamily] || FONT_FAMILIES.Helvetica).css,
+          fontWeight: overlay.bold ? 700 : 400,
+          fontStyle: overlay.italic ? "italic" : "normal",
+          lineHeight: overlay.lineHeight || 1.2,
+          width: overlay.width ? `${overlay.width}%` : undefined,
+          height: overlay.height ? `${overlay.height}%` : undefined,
+          justifyContent: VERTICAL_ALIGN[overlay.verticalAlign] || "flex-start",
+          ...textDecorationStyle(overlay),
         }}
         onMouseDown={onMouseDown}
         onClick={(e) => {
@@ -251,15 +298,23 @@ function OverlayElement({
           ×
         </button>
         {isSelected && editing ? (
-          <input
+          <textarea
             ref={inputRef}
-            type="text"
             value={editValue}
+            rows={1}
             style={{
               fontSize: `${overlay.fontSize}px`,
+              fontFamily: "inherit",
+              fontWeight: "inherit",
+              fontStyle: "inherit",
+              lineHeight: "inherit",
               color: overlay.color || "#000000",
-              minWidth: 40,
-              maxWidth: 200,
+              // A sized box wraps like the output; otherwise fit the longest line
+              width: overlay.width
+                ? "100%"
+                : `${Math.max(4, ...editValue.split("\n").map((line) => line.length)) + 1}ch`,
+              resize: "none",
+              overflow: "hidden",
             }}
             onChange={e => setEditValue(e.target.value)}
             onBlur={() => {
@@ -269,7 +324,9 @@ function OverlayElement({
               }
             }}
             onKeyDown={e => {
-              if (e.key === "Enter") {
+              // Enter adds a line, as in the sidebar; Ctrl/Cmd+Enter commits
+              if (e.key === "Enter" && (e.ctrlKey || e.metaKey)) {
+                e.preventDefault();
                 inputRef.current.blur();
               } else if (e.key === "Escape") {
                 setEditValue(overlay.text);
@@ -278,12 +335,103 @@ function OverlayElement({
             }}
           />
         ) : (
-          <span>{overlay.text}</span>
+          <span
+            style={{
+              opacity: overlay.opacity ?? 1,
+              mixBlendMode: blendPreview(overlay),
+            }}
+          >
+            {overlay.text}
+          </span>
         )}
       </div>
     );
   }
 
+  if (overlay.type === "highlight") {
+    return (
+      <div
+        className={`overlay-element overlay-highlight ${isSelected ? "selected" : ""}`}
+        style={{
+          ...style,
+          width: `${overlay.width}%`,
+          height: `${overlay.height}%`,
+        }}
+        onMouseDown={onMouseDown}
+        onClick={(e) => e.stopPropagation()}
+      >
+        <button className="delete-btn" onClick={onDelete}>
+          ×
+        </button>
+        <div
+          className="highlight-fill"
+          style={{
+            background: overlay.color || "#ffeb3b",
+            opacity: overlay.opacity ?? 0.4,
+          }}
+        />
+      </div>
+    );
+  }
+
+  if (overlay.type === "stamp") {
+    const glyph = STAMP_GLYPHS[overlay.glyph] || STAMP_GLYPHS.check;
+    const color = overlay.color || "#000000";
+    return (
+      <div
+        className={`overlay-element overlay-stamp ${isSelected ? "selected" : ""}`}
+        style={style}
+        onMouseDown={onMouseDown}
+        onClick={(e) => e.stopPropagation()}
+      >
+        <button className="delete-btn" onClick={onDelete}>
+          ×
+        </button>
+        <svg
+          width={overlay.size}
+          height={overlay.size}
+          viewBox="0 0 1 1"
+        >
+          <path
+            d={glyph.path}
+            fill={glyph.fill ? color : "none"}
+            stroke={glyph.fill ? "none" : color}
+            strokeWidth={STAMP_STROKE}
+            strokeLinecap="round"
+          />
+        </svg>
+      </div>
+    );
+  }
+
+  if (overlay.type === "pdf") {
+    const widthPx = (overlay.width / 100) * containerWidth;
+    const heightPx = (overlay.height / 100) * containerHeight;
+    return (
+      <div
+        className={`overlay-element overlay-image ${isSelected ? "selected" : ""}`}
+        style={style}
+        onMouseDown={onMouseDown}
+        onClick={(e) => e.stopPropagation()}
+      >
+        <button className="delete-btn" onClick={onDelete}>
+          ×
+        </button>
+        <PDFStampPreview
+          pdfData={overlay.pdfData}
+          sourcePage={overlay.sourcePage}
+          style={{
+            width: `${widthPx}px`,
+            height: `${heightPx}px`,
+            opacity: overlay.opacity ?? 1,
+            objectFit: overlay.preserveAspect ? "contain" : "fill",
+            mixBlendMode: blendPreview(overlay),
+          }}
+        />
+      </div>
+    );
+  }
+
   if (overlay.type === "image") {
     const widthPx = (overlay.width / 100) * containerWidth;
     const heightPx = (overlay.height / 100) * containerHeight;
@@ -300,7 +448,13 @@ function OverlayElement({
         <img
           src={overlay.imageData}
           alt={overlay.fileName || "Image"}
-          style={{ width: `${widthPx}px`, height: `${heightPx}px` }}
+          style={{
+            width: `${widthPx}px`,
+            height: `${heightPx}px`,
+            opacity: overlay.opacity ?? 1,
+            objectFit: overlay.preserveAspect ? "contain" : "fill",
+            mixBlendMode: blendPreview(overlay),
+          }}
         />
       </div>
     );
@@ -308,3 +462,37 @@ function OverlayElement({
 
   return null;
 }
+
+/**
+ * Render one page of a stamp PDF (held as a data URL) onto a canvas.
+ */
+function PDFStampPreview({ pdfData, sourcePage, style }) {
+  const canvasRef = useRef(null);
+
+  useEffect(() => {
+    let cancelled = false;
+    let loadingTask;
+    fetch(pdfData)
+      .then((res) => res.arrayBuffer())
+      .then((data) => {
+        loadingTask = pdfjsLib.getDocument({ data: new Uint8Array(data) });
+        return loadingTask.promise;
+      })
+      .then((doc) => doc.getPage(sourcePage || 1))
+      .then((page) => {
+        if (cancelled || !canvasRef.current) return;
+        const viewport = page.getViewport({ scale: 1.5 });
+        const canvas = canvasRef.current;
+        canvas.width = viewport.width;
+        canvas.height = viewport.height;
+        return page.render({ canvasContext: canvas.getContext("2d"), viewport }).promise;
+      })
+      .catch((err) => console.warn("PDF stamp preview error:", err));
+    return () => {
+      cancelled = true;
+      loadingTask?.destroy();
+    };
+  }, [pdfData, sourcePage]);
+
+  return <canvas ref={canvasRef} style={{ display: "block", pointerEvents: "none", ...style }} />;
+}
@@ -0,0 +1,91 @@
+import React from "react";
+import { PAGE_LABEL_STYLES } from "../pageLabels";
+
+export default function PageLabelsPanel({ pageLabels, onChange, totalPages }) {
+  const update = (index, updates) =>
+    onChange(pageLabels.map((r, i) => (i === index ? { ...r, ...updates } : r)));
+
+  const nextStart = Math.min(
+    totalPages,
+    pageLabels.reduce((max, r) => Math.max(max, r.startPage), 0) + 1
+  );
+
+  return (
+    <div className="panel">
+      <h3>Page Labels</h3>
+      <p style={{ fontSize: 12, color: "#888", marginBottom: 8 }}>
+        Logical page numbers shown by PDF viewers, e.g. i–iv then 1, 2, 3.
+      </p>
+      {pageLabels.map((range, i) => (
+        <div key={i} className="form-row">
+          <div className="form-group">
+            <label>From Page</label>
+            <input
+              type="number"
+              min={1}
+              max={totalPages}
+              value={range.startPage}
+              onChange={(e) =>
+                update(i, {
+                  startPage: Math.min(totalPages, Math.max(1, parseInt(e.target.value) || 1)),
+                })
+              }
+            />
+          </div>
+          <div className="form-group">
+            <label>Style</label>
+            <select
+              value={range.style}
+              onChange={(e) => update(i, { style: e.target.value })}
+            >
+              {Object.entries(PAGE_LABEL_STYLES).map(([style, label]) => (
+                <option key={style} value={style}>
+                  {label}
+                </option>
+              ))}
+            </select>
+          </div>
+          <div className="form-group">
+            <label>Prefix</label>
+            <input
+              type="text"
+              placeholder="e.g. A-"
+              value={range.prefix}
+              onChange={(e) => update(i, { prefix: e.target.value })}
+            />
+          </div>
+          <div className="form-group">
+            <label>Start</label>
+            <input
+              type="number"
+              min={1}
+              value={range.start}
+              onChange={(e) =>
+                update(i, { start: Math.max(1, parseInt(e.target.value) || 1) })
+              }
+            />
+          </div>
+          <button
+            className="btn btn-danger btn-sm"
+            style={{ height: 34, alignSelf: "flex-end" }}
+            title="Remove range"
+            onClick={() => onChange(pageLabels.filter((_, j) => j !== i))}
+          >
+            ✕
+          </button>
+        </div>
+      ))}
+      <button
+        className="btn btn-sm"
+        onClick={() =>
+          onChange([
+            ...pageLabels,
+            { startPage: pageLabels.length ? nextStart : 1, style: "D", prefix: "", start: 1 },
+          ])
+        }
+      >
+        + Add Range
+      </button>
+    </div>
+  );
+}
@@ -0,0 +1,94 @@
+import React, { useState } from "react";
+
+const EDGES = ["top", "right", "bottom", "left"];
+
+export default function PageSetupPanel({
+  onNormalizeOrientation,
+  onAdjustMargins,
+  onAutoCrop,
+  processing,
+}) {
+  const [orientation, setOrientation] = useState("portrait");
+  const [mode, setMode] = useState("rotate");
+  const [margins, setMargins] = useState({ top: 0, right: 0, bottom: 0, left: 0 });
+  const [paddingMm, setPaddingMm] = useState(5);
+
+  return (
+    <div className="panel">
+      <h3>Page Setup</h3>
+      <p style={{ fontSize: 12, color: "#888", marginBottom: 8 }}>
+        Changes the working document itself; placed elements keep their
+        relative positions.
+      </p>
+      <div className="form-row">
+        <div className="form-group">
+          <label>Orientation</label>
+          <select value={orientation} onChange={(e) => setOrientation(e.target.value)}>
+            <option value="portrait">All portrait</option>
+            <option value="landscape">All landscape</option>
+          </select>
+        </div>
+        <div className="form-group">
+          <label>Other Pages</label>
+          <select value={mode} onChange={(e) => setMode(e.target.value)}>
+            <option value="rotate">Rotate</option>
+            <option value="scale">Scale to fit</option>
+          </select>
+        </div>
+        <button
+          className="btn btn-sm"
+          style={{ height: 34, alignSelf: "flex-end" }}
+          disabled={processing}
+          onClick={() => onNormalizeOrientation({ orientation, mode })}
+        >
+          Apply
+        </button>
+      </div>
+      <label style={{ fontSize: 12, fontWeight: 600, color: "#555" }}>
+        Margins (mm, negative trims)
+      </label>
+      <div className="form-row">
+        {EDGES.map((edge) => (
+          <div key={edge} className="form-group">
+            <label style={{ textTransform: "capitalize" }}>{edge}</label>
+            <input
+              type="number"
+              step={1}
+              value={margins[edge]}
+              onChange={(e) =>
+                setMargins({ ...margins, [edge]: parseFloat(e.target.value) || 0 })
+              }
+            />
+          </div>
+        ))}
+        <button
+          className="btn btn-sm"
+          style={{ height: 34, alignSelf: "flex-end" }}
+          disabled={processing || EDGES.every((edge) => !margins[edge])}
+          onClick={() => onAdjustMargins(margins)}
+        >
+          Apply
+        </button>
+      </div>
+      <div className="form-row">
+        <div className="form-group">
+          <label>Crop to Content, Padding (mm)</label>
+          <input
+            type="number"
+            min={0}
+            value={paddingMm}
+            onChange={(e) => setPaddingMm(Math.max(0, parseFloat(e.target.value) || 0))}
+          />
+        </div>
+        <button
+          className="btn btn-sm"
+          style={{ height: 34, alignSelf: "flex-end" }}
+          disabled={processing}
+          onClick={() => onAutoCrop(paddingMm)}
+        >
+          ✂️ Auto-crop
+        </button>
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,165 @@
+import React, { useState } from "react";
+import { SHEET_SIZES } from "../poster";
+
+export default function PagesPanel({
+  totalPages,
+  currentPage,
+  onInsertToc,
+  onPoster,
+  posterResult,
+  onRemoveBlankPages,
+  processing,
+}) {
+  const [tocTitle, setTocTitle] = useState("Contents");
+  const [tocBefore, setTocBefore] = useState(1);
+  const [tocSource, setTocSource] = useState("1"); // bookmark depth, or "typed"
+  const [tocText, setTocText] = useState("");
+  const [sheet, setSheet] = useState("A4");
+  const [scalePercent, setScalePercent] = useState(100);
+  const [overlapMm, setOverlapMm] = useState(10);
+  const [blankPercent, setBlankPercent] = useState(0.5);
+
+  const insertToc = () =>
+    onInsertToc({
+      title: tocTitle.trim(),
+      before: tocBefore,
+      ...(tocSource === "typed" ? { entriesText: tocText } : { depth: parseInt(tocSource) }),
+    });
+
+  return (
+    <div className="panel">
+      <h3>Pages</h3>
+      <p style={{ fontSize: 12, color: "#888", marginBottom: 8 }}>
+        Contents pages and blank page removal change the working document
+        itself; placed elements move with their pages. Posters are saved as a
+        separate PDF.
+      </p>
+      <label style={{ fontSize: 12, fontWeight: 600, color: "#555" }}>Table of Contents</label>
+      <div className="form-row">
+        <div className="form-group">
+          <label>Title</label>
+          <input type="text" value={tocTitle} onChange={(e) => setTocTitle(e.target.value)} />
+        </div>
+        <div className="form-group">
+          <label>Before Page</label>
+          <input
+            type="number"
+            min={1}
+            max={totalPages + 1}
+            value={tocBefore}
+            onChange={(e) =>
+              setTocBefore(Math.min(totalPages + 1, Math.max(1, parseInt(e.target.value) || 1)))
+            }
+          />
+        </div>
+      </div>
+      <div className="form-group">
+        <label>Entries</label>
+        <select value={tocSource} onChange={(e) => setTocSource(e.target.value)}>
+          <option value="1">Top-level bookmarks</option>
+          <option value="2">Bookmarks, two levels</option>
+          <option value="99">All bookmarks</option>
+          <option value="typed">Typed below</option>
+        </select>
+      </div>
+      {tocSource === "typed" && (
+        <div className="form-group">
+          <textarea
+            rows={4}
+            placeholder={"Introduction | 1\n  Background | 2\nResults | 5"}
+            value={tocText}
+            onChange={(e) => setTocText(e.target.value)}
+          />
+          <span style={{ fontSize: 11, color: "#aaa" }}>
+            One "Title | page" per line; indent two spaces to nest
+          </span>
+        </div>
+      )}
+      <button
+        className="btn btn-sm"
+        disabled={processing || (tocSource === "typed" && !tocText.trim())}
+        onClick={insertToc}
+      >
+        📑 Insert Contents Page
+      </button>
+      {tocBefore > totalPages && (
+        <span style={{ fontSize: 11, color: "#aaa", marginLeft: 6 }}>at the end</span>
+      )}
+
+      <label style={{ display: "block", marginTop: 12, fontSize: 12, fontWeight: 600, color: "#555" }}>
+        Poster of Page {currentPage}
+      </label>
+      <div className="form-row">
+        <div className="form-group">
+          <label>Sheets</label>
+          <select value={sheet} onChange={(e) => setSheet(e.target.value)}>
+            {Object.entries(SHEET_SIZES).map(([key, s]) => (
+              <option key={key} value={key}>
+                {s.label}
+              </option>
+            ))}
+          </select>
+        </div>
+        <div className="form-group">
+          <label>Scale (%)</label>
+          <input
+            type="number"
+            min={1}
+            value={scalePercent}
+            onChange={(e) => setScalePercent(Math.max(1, parseFloat(e.target.value) || 100))}
+          />
+        </div>
+        <div className="form-group">
+          <label>Overlap (mm)</label>
+          <input
+            type="number"
+            min={0}
+            value={overlapMm}
+            onChange={(e) => setOverlapMm(Math.max(0, parseFloat(e.target.value) || 0))}
+          />
+        </div>
+      </div>
+      <button
+        className="btn btn-sm"
+        disabled={processing}
+        onClick={() => onPoster({ sheet, scale: scalePercent / 100, overlapMm })}
+      >
+        🧩 Tile Across Sheets
+      </button>
+      {posterResult && (
+        <a
+          className="btn btn-sm btn-success"
+          style={{ marginLeft: 6, textDecoration: "none" }}
+          href={posterResult.url}
+          download={posterResult.name}
+        >
+          ⬇️ {posterResult.name}
+        </a>
+      )}
+
+      <label style={{ display: "block", marginTop: 12, fontSize: 12, fontWeight: 600, color: "#555" }}>
+        Blank Pages
+      </label>
+      <div className="form-row">
+        <div className="form-group">
+          <label>Blank Below Ink Coverage (%)</label>
+          <input
+            type="number"
+            min={0}
+            step={0.1}
+            value={blankPercent}
+            onChange={(e) => setBlankPercent(Math.max(0, parseFloat(e.target.value) || 0))}
+          />
+        </div>
+        <button
+          className="btn btn-sm"
+          style={{ height: 34, alignSelf: "flex-end" }}
+          disabled={processing || totalPages < 2}
+          onClick={() => onRemoveBlankPages(blankPercent)}
+        >
+          🗑️ Remove Blank Pages
+        </button>
+      </div>
+    </div>
+  );
+}
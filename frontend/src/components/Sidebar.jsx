@@ -1,23 +1,92 @@
 import React, { useState } from "react";
 import ImageUploader from "./ImageUploader";
+import PDFStampUploader from "./PDFStampUploader";
+import TemplatesPanel from "./TemplatesPanel";
+import SignatureLibrary from "./SignatureLibrary";
+import MailMergePanel from "./MailMergePanel";
+import BatchPanel from "./BatchPanel";
+import PageLabelsPanel from "./PageLabelsPanel";
+import PagesPanel from "./PagesPanel";
+import OutputList from "./OutputList";
+import FormDataPanel from "./FormDataPanel";
+import PageSetupPanel from "./PageSetupPanel";
+import DiagnosticsList from "./DiagnosticsList";
+import ColorInput from "./ColorInput";
+import { loadSignatures, saveSignature } from "../signatures";
+import { STAMP_GLYPHS } from "../stamps";
+import { IMAGE_QUALITY } from "../imageQuality";
+import { FONT_FAMILIES } from "../fonts";
+import { isOnPage } from "../pages";
+import { UNITS, fromPercent, toPercent } from "../units";
+import { sanitizeFileName } from "../fileNames";
+import { overlayLabel } from "../overlayLabels";
 
 export default function Sidebar({
   activeTool,
   setActiveTool,
   overlays,
   currentPage,
+  totalPages,
+  pageSizes,
   selectedOverlay,
   setSelectedOverlay,
   onUpdateOverlay,
   onDeleteOverlay,
+  onReorderOverlay,
   onAddImage,
+  onAddPdfStamp,
+  onAddBates,
+  onApplyTemplate,
   onProcess,
+  onMailMerge,
+  mergeResults,
+  imageQuality,
+  onImageQualityChange,
+  sanitize,
+  onSanitizeChange,
+  scrub,
+  onScrubChange,
+  skipFailures,
+  onSkipFailuresChange,
+  hasEdits,
+  formFieldCount,
+  formData,
+  onExportForm,
+  onImportForm,
+  onClearForm,
+  onExportNavigation,
+  onValidate,
+  diagnostics,
+  onShowOverlay,
+  onNormalizeOrientation,
+  onAdjustMargins,
+  onAutoCrop,
+  pageLabels,
+  onPageLabelsChange,
+  onInsertToc,
+  onPoster,
+  posterResult,
+  onRemoveBlankPages,
+  onBatch,
+  batchResults,
+  onSplitByBookmarks,
+  splitResults,
+  splitZipName,
   onReset,
+  onCancel,
   processing,
+  progress,
   downloadUrl,
+  downloadName,
 }) {
   const [showImageUploader, setShowImageUploader] = useState(false);
+  const [showPdfStampUploader, setShowPdfStampUploader] = useState(false);
+  const [signatures, setSignatures] = useState(loadSignatures);
+  const [customName, setCustomName] = useState("");
+  const [units, setUnits] = useState("percent");
+  const [origin, setOrigin] = useState("top-left");
   const selected = overlays.find((o) => o.id === selectedOverlay);
+  const pageOverlays = overlays.filter((o) => isOnPage(o, currentPage, totalPages));
 
   return (
     <>
@@ -33,12 +102,37 @@ export default function Sidebar({
           >
             ✏️ Add Text
           </button>
+          <button
+            className={`btn ${activeTool === "highlight" ? "btn-active" : ""}`}
+            onClick={() =>
+              setActiveTool(activeTool === "highlight" ? null : "highlight")
+            }
+          >
+            🖍️ Highlight
+          </button>
+          <button
+            className={`btn ${activeTool === "stamp" ? "btn-active" : ""}`}
+            onClick={() =>
+              setActiveTool(activeTool === "stamp" ? null : "stamp")
+            }
+          >
+            ☑️ Stamp
+          </button>
           <button
             className={`btn ${showImageUploader ? "btn-active" : ""}`}
             onClick={() => setShowImageUploader(!showImageUploader)}
           >
             🖼️ Add Image
           </button>
+          <button
+            className={`btn ${showPdfStampUploader ? "btn-active" : ""}`}
+            onClick={() => setShowPdfStampUploader(!showPdfStampUploader)}
+          >
+            📄 PDF Stamp
+          </button>
+          <button className="btn" onClick={onAddBates}>
+            #️⃣ Bates
+          </button>
         </div>
 
         {activeTool === "text" && (
@@ -47,6 +141,18 @@ export default function Sidebar({
           </p>
         )}
 
+        {activeTool === "highlight" && (
+          <p style={{ fontSize: 12, color: "#888", marginTop: 4 }}>
+            Click on the PDF where the highlight should start
+          </p>
+        )}
+
+        {activeTool === "stamp" && (
+          <p style={{ fontSize: 12, color: "#888", marginTop: 4 }}>
+            Click on the PDF to place a checkmark, cross, or box
+          </p>
+        )}
+
         {showImageUploader && (
           <ImageUploader
             onUpload={(dataUrl, fileName) => {
@@ -56,6 +162,16 @@ export default function Sidebar({
             onCancel={() => setShowImageUploader(false)}
           />
         )}
+
+        {showPdfStampUploader && (
+          <PDFStampUploader
+            onUpload={(dataUrl, fileName) => {
+              onAddPdfStamp(dataUrl, fileName);
+              setShowPdfStampUploader(false);
+            }}
+            onCancel={() => setShowPdfStampUploader(false)}
+          />
+        )}
       </div>
 
       {/* Properties Panel */}
@@ -73,6 +189,100 @@ export default function Sidebar({
                     onUpdateOverlay(selected.id, { text: e.target.value })
                   }
                 />
+                <span style={{ fontSize: 11, color: "#aaa" }}>
+                  Variables: {"{date} {time} {page} {totalPages} {filename}"}
+                  {selected.bates && " {bates}"}
+                </span>
+              </div>
+              {selected.bates && (
+                <div className="form-row">
+                  <div className="form-group">
+                    <label>Bates Prefix</label>
+                    <input
+                      type="text"
+                      placeholder="e.g. ABC"
+                      value={selected.bates.prefix}
+                      onChange={(e) =>
+                        onUpdateOverlay(selected.id, {
+                          bates: { ...selected.bates, prefix: e.target.value },
+                        })
+                      }
+                    />
+                  </div>
+                  <div className="form-group">
+                    <label>Start</label>
+                    <input
+                      type="number"
+                      min={0}
+                      value={selected.bates.start}
+                      onChange={(e) =>
+                        onUpdateOverlay(selected.id, {
+                          bates: {
+                            ...selected.bates,
+                            start: Math.max(0, parseInt(e.target.value) || 0),
+                          },
+                        })
+                      }
+                    />
+                  </div>
+                  <div className="form-group">
+                    <label>Digits</label>
+                    <input
+                      type="number"
+                      min={1}
+                      max={12}
+                      value={selected.bates.digits}
+                      onChange={(e) =>
+                        onUpdateOverlay(selected.id, {
+                          bates: {
+                            ...selected.bates,
+                            digits: Math.min(12, Math.max(1, parseInt(e.target.value) || 1)),
+                          },
+                        })
+                      }
+                    />
+                  </div>
+                </div>
+              )}
+              <div className="form-row">
+                <div className="form-group">
+                  <label>Font</label>
+                  <select
+                    value={selected.fontFamily || "Helvetica"}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, { fontFamily: e.target.value })
+                    }
+                  >
+                    {Object.entries(FONT_FAMILIES).map(([key, f]) => (
+                      <option key={key} value={key}>
+                        {f.label}
+                      </option>
+                    ))}
+                  </select>
+                </div>
+                <div className="form-group">
+                  <label>Style</label>
+                  <div className="toolbar" style={{ marginBottom: 0 }}>
+                    <button
+                      className={`btn btn-sm ${selected.bold ? "btn-active" : ""}`}
+                      style={{ fontWeight: 700 }}
+                      onClick={() =>
+                        onUpdateOverlay(selected.id, { bold: !selected.bold })
+                      }
+                    >
+                      B
+                    </button>
+                    <button
+                      className={`btn btn-sm ${selected.italic ? "btn-active" : ""}`}
+                      style={{ fontStyle: "italic" }}
+                      onClick={() =>
+                        onUpdateOverlay(selected.id, { italic: !selected.italic })
+                      }
+                    >
+                      I
+                    </button>
+                  </div>
+                </div>
               </div>
               <div className="form-row">
                 <div className="form-group">
@@ -91,25 +301,327 @@ export default function Sidebar({
                 </div>
                 <div className="form-group">
                   <label>Color</label>
+                  <ColorInput
+                    value={selected.color}
+                    fallback="#000000"
+                    onChange={(color) => onUpdateOverlay(selected.id, { color })}
+                  />
+                </div>
+              </div>
+              <div className="form-row">
+                <div className="form-group">
+                  <label>Box Width (%)</label>
+                  <input
+                    type="number"
+                    min={0}
+                    max={100}
+                    value={selected.width || 0}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        width: parseInt(e.target.value) || 0,
+                      })
+                    }
+                  />
+                </div>
+                <div className="form-group">
+                  <label>Box Height (%)</label>
+                  <input
+                    type="number"
+                    min={0}
+                    max={100}
+                    value={selected.height || 0}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        height: parseInt(e.target.value) || 0,
+                      })
+                    }
+                  />
+                </div>
+              </div>
+              <span style={{ fontSize: 11, color: "#aaa" }}>
+                Set a box width to wrap text; 0 keeps a single line per row
+              </span>
+              <div className="form-row">
+                <div className="form-group">
+                  <label>Line Height</label>
+                  <input
+                    type="number"
+                    min={0.8}
+                    max={3}
+                    step={0.1}
+                    value={selected.lineHeight || 1.2}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        lineHeight: parseFloat(e.target.value) || 1.2,
+                      })
+                    }
+                  />
+                </div>
+                <div className="form-group">
+                  <label>Vertical Align</label>
+                  <select
+                    value={selected.verticalAlign || "top"}
+                    disabled={!selected.height}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        verticalAlign: e.target.value,
+                      })
+                    }
+                  >
+                    <option value="top">Top</option>
+                    <option value="middle">Middle</option>
+                    <option value="bottom">Bottom</option>
+                  </select>
+                </div>
+              </div>
+              <div className="form-row">
+                <div className="form-group">
+                  <label>Rotation (°)</label>
+                  <input
+                    type="number"
+                    min={-180}
+                    max={180}
+                    value={selected.rotation || 0}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        rotation: parseInt(e.target.value) || 0,
+                      })
+                    }
+                  />
+                </div>
+                <div className="form-group">
+                  <label>Opacity</label>
                   <input
-                    type="color"
-                    value={selected.color || "#000000"}
+                    type="number"
+                    min={0.1}
+                    max={1}
+                    step={0.1}
+                    value={selected.opacity ?? 1}
                     onChange={(e) =>
-                      onUpdateOverlay(selected.id, { color: e.target.value })
+                      onUpdateOverlay(selected.id, {
+                        opacity: parseFloat(e.target.value) || 1,
+                      })
                     }
                   />
                 </div>
               </div>
+              <div className="form-row">
+                <div className="form-group">
+                  <label>Outline (pt)</label>
+                  <input
+                    type="number"
+                    min={0}
+                    max={10}
+                    step={0.5}
+                    value={selected.strokeWidth || 0}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        strokeWidth: parseFloat(e.target.value) || 0,
+                      })
+                    }
+                  />
+                </div>
+                <div className="form-group">
+                  <label>Outline Color</label>
+                  <ColorInput
+                    value={selected.strokeColor}
+                    fallback="#ffffff"
+                    onChange={(strokeColor) => onUpdateOverlay(selected.id, { strokeColor })}
+                  />
+                </div>
+              </div>
+              <div className="form-group">
+                <label>
+                  <input
+                    type="checkbox"
+                    style={{ width: "auto", marginRight: 6 }}
+                    checked={!!selected.backgroundColor}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        backgroundColor: e.target.checked ? "#ffffff" : undefined,
+                      })
+                    }
+                  />
+                  Background box
+                </label>
+              </div>
+              {selected.backgroundColor && (
+                <div className="form-row">
+                  <div className="form-group">
+                    <label>Fill</label>
+                    <ColorInput
+                      value={selected.backgroundColor}
+                      fallback="#ffffff"
+                      onChange={(backgroundColor) =>
+                        onUpdateOverlay(selected.id, { backgroundColor })
+                      }
+                    />
+                  </div>
+                  <div className="form-group">
+                    <label>Padding (pt)</label>
+                    <input
+                      type="number"
+                      min={0}
+                      max={40}
+                      value={selected.backgroundPadding ?? 4}
+                      onChange={(e) =>
+                        onUpdateOverlay(selected.id, {
+                          backgroundPadding: parseFloat(e.target.value) || 0,
+                        })
+                      }
+                    />
+                  </div>
+                  <div className="form-group">
+                    <label>Corners (pt)</label>
+                    <input
+                      type="number"
+                      min={0}
+                      max={40}
+                      value={selected.backgroundRadius || 0}
+                      onChange={(e) =>
+                        onUpdateOverlay(selected.id, {
+                          backgroundRadius: parseFloat(e.target.value) || 0,
+                        })
+                      }
+                    />
+                  </div>
+                </div>
+              )}
             </>
           )}
-          {selected.type === "image" && (
+          {selected.type === "highlight" && (
             <>
+              <div className="form-row">
+                <div className="form-group">
+                  <label>Color</label>
+                  <ColorInput
+                    value={selected.color}
+                    fallback="#ffeb3b"
+                    onChange={(color) => onUpdateOverlay(selected.id, { color })}
+                  />
+                </div>
+                <div className="form-group">
+                  <label>Opacity</label>
+                  <input
+                    type="number"
+                    min={0.1}
+                    max={1}
+                    step={0.1}
+                    value={selected.opacity}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        opacity: parseFloat(e.target.value) || 0.4,
+                      })
+                    }
+                  />
+                </div>
+              </div>
+              <div className="form-group">
+                <label>Width (%)</label>
+                <input
+                  type="range"
+                  min={1}
+                  max={100}
+                  value={selected.width}
+                  onChange={(e) =>
+                    onUpdateOverlay(selected.id, {
+                      width: parseInt(e.target.value),
+                    })
+                  }
+                />
+                <span style={{ fontSize: 12, color: "#888" }}>
+                  {selected.width}%
+                </span>
+              </div>
+              <div className="form-group">
+                <label>Height (%)</label>
+                <input
+                  type="range"
+                  min={1}
+                  max={50}
+                  value={selected.height}
+                  onChange={(e) =>
+                    onUpdateOverlay(selected.id, {
+                      height: parseInt(e.target.value),
+                    })
+                  }
+                />
+                <span style={{ fontSize: 12, color: "#888" }}>
+                  {selected.height}%
+                </span>
+              </div>
+            </>
+          )}
+          {selected.type === "stamp" && (
+            <>
+              <div className="form-group">
+                <label>Glyph</label>
+                <select
+                  value={selected.glyph}
+                  onChange={(e) =>
+                    onUpdateOverlay(selected.id, { glyph: e.target.value })
+                  }
+                >
+                  {Object.entries(STAMP_GLYPHS).map(([key, g]) => (
+                    <option key={key} value={key}>
+                      {g.label}
+                    </option>
+                  ))}
+                </select>
+              </div>
+              <div className="form-row">
+                <div className="form-group">
+                  <label>Size</label>
+                  <input
+                    type="number"
+                    min={6}
+                    max={72}
+                    value={selected.size}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        size: parseInt(e.target.value) || 16,
+                      })
+                    }
+                  />
+                </div>
+                <div className="form-group">
+                  <label>Color</label>
+                  <ColorInput
+                    value={selected.color}
+                    fallback="#000000"
+                    onChange={(color) => onUpdateOverlay(selected.id, { color })}
+                  />
+                </div>
+              </div>
+            </>
+          )}
+          {(selected.type === "image" || selected.type === "pdf") && (
+            <>
+              {selected.type === "pdf" && (
+                <div className="form-group">
+                  <label>Source Page (of {selected.sourcePages})</label>
+                  <input
+                    type="number"
+                    min={1}
+                    max={selected.sourcePages}
+                    value={selected.sourcePage}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        sourcePage: Math.min(
+                          selected.sourcePages,
+                          Math.max(1, parseInt(e.target.value) || 1)
+                        ),
+                      })
+                    }
+                  />
+                </div>
+              )}
               <div className="form-group">
                 <label>Width (%)</label>
                 <input
                   type="range"
                   min={5}
-                  max={80}
+                  max={selected.type === "pdf" ? 100 : 80}
                   value={selected.width}
                   onChange={(e) =>
                     onUpdateOverlay(selected.id, {
@@ -126,7 +638,7 @@ export default function Sidebar({
                 <input
                   type="range"
                   min={5}
-                  max={80}
+                  max={selected.type === "pdf" ? 100 : 80}
                   value={selected.height}
                   onChange={(e) =>
                     onUpdateOverlay(selected.id, {
@@ -138,56 +650,176 @@ export default function Sidebar({
                   {selected.height}%
                 </span>
               </div>
+              <div className="form-group">
+                <label>
+                  <input
+                    type="checkbox"
+                    style={{ width: "auto", marginRight: 6 }}
+                    checked={!!selected.preserveAspect}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        preserveAspect: e.target.checked,
+                      })
+                    }
+                  />
+                  Preserve aspect ratio
+                </label>
+              </div>
+              {selected.type === "image" && (
+                <button
+                  className="btn btn-sm"
+                  style={{ marginBottom: 12 }}
+                  onClick={() => {
+                    try {
+                      setSignatures(saveSignature(selected.fileName, selected.imageData));
+                    } catch (e) {
+                      alert(e.message);
+                    }
+                  }}
+                >
+                  ⭐ Save to library
+                </button>
+              )}
+              <div className="form-row">
+                <div className="form-group">
+                  <label>Rotation (°)</label>
+                  <input
+                    type="number"
+                    min={-180}
+                    max={180}
+                    value={selected.rotation || 0}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        rotation: parseInt(e.target.value) || 0,
+                      })
+                    }
+                  />
+                </div>
+                <div className="form-group">
+                  <label>Opacity</label>
+                  <input
+                    type="number"
+                    min={0.1}
+                    max={1}
+                    step={0.1}
+                    value={selected.opacity ?? 1}
+                    onChange={(e) =>
+                      onUpdateOverlay(selected.id, {
+                        opacity: parseFloat(e.target.value) || 1,
+                      })
+                    }
+                  />
+                </div>
+              </div>
             </>
           )}
-          <button
-            className="btn btn-danger btn-sm"
-            style={{ marginTop: 8 }}
-            onClick={() => onDeleteOverlay(selected.id)}
-          >
-            🗑️ Delete
-          </button>
+          {["text", "image", "pdf"].includes(selected.type) && (
+            <div className="form-group">
+              <label>
+                <input
+                  type="checkbox"
+                  style={{ width: "auto", marginRight: 6 }}
+                  checked={!!selected.background}
+                  onChange={(e) =>
+                    onUpdateOverlay(selected.id, { background: e.target.checked })
+                  }
+                />
+                Behind page content
+              </label>
+              <label>
+                <input
+                  type="checkbox"
+                  style={{ width: "auto", marginRight: 6 }}
+                  checked={!!selected.multiply}
+                  onChange={(e) =>
+                    onUpdateOverlay(selected.id, { multiply: e.target.checked })
+                  }
+                />
+                Multiply blend (page ink shows through)
+              </label>
+            </div>
+          )}
+          <PositionFields
+            overlay={selected}
+            pageSize={pageSizes?.[selected.page - 1]}
+            units={units}
+            setUnits={setUnits}
+            origin={origin}
+            setOrigin={setOrigin}
+            onUpdateOverlay={onUpdateOverlay}
+          />
+          <div className="form-group">
+            <label>Apply to Pages</label>
+            <input
+              type="text"
+              placeholder={`Only page ${selected.page} (e.g. 1-3,7, all, even)`}
+              value={selected.pages || ""}
+              onChange={(e) =>
+                onUpdateOverlay(selected.id, { pages: e.target.value })
+              }
+            />
+          </div>
+          <div className="toolbar" style={{ marginTop: 8, marginBottom: 0 }}>
+            <button
+              className="btn btn-sm"
+              title="Bring forward"
+              onClick={() => onReorderOverlay(selected.id, "up")}
+            >
+              ▲ Forward
+            </button>
+            <button
+              className="btn btn-sm"
+              title="Send backward"
+              onClick={() => onReorderOverlay(selected.id, "down")}
+            >
+              ▼ Backward
+            </button>
+            <button
+              className="btn btn-danger btn-sm"
+              onClick={() => onDeleteOverlay(selected.id)}
+            >
+              🗑️ Delete
+            </button>
+          </div>
         </div>
       )}
 
       {/* Overlays List */}
       <div className="panel">
         <h3>
-          Elements on Page {currentPage} ({overlays.filter((o) => o.page === currentPage).length})
+          Elements on Page {currentPage} ({pageOverlays.length})
         </h3>
         <div className="overlay-list">
-          {overlays
-            .filter((o) => o.page === currentPage)
-            .map((o) => (
-              <div
-                key={o.id}
-                className="overlay-item"
-                onClick={() => setSelectedOverlay(o.id)}
-                style={{
-                  background:
-                    selectedOverlay === o.id ? "#e8f0fe" : undefined,
+          {pageOverlays.map((o) => (
+            <div
+              key={o.id}
+              className="overlay-item"
+              onClick={() => setSelectedOverlay(o.id)}
+              style={{
+                background:
+                  selectedOverlay === o.id ? "#e8f0fe" : undefined,
+              }}
+            >
+              <span>
+                <span
+                  className={`type-badge ${o.type}`}
+                >
+                  {o.type}
+                </span>{" "}
+                {overlayLabel(o)}
+              </span>
+              <button
+                className="btn btn-danger btn-sm"
+                onClick={(e) => {
+                  e.stopPropagation();
+                  onDeleteOverlay(o.id);
                 }}
               >
-                <span>
-                  <span
-                    className={`type-badge ${o.type}`}
-                  >
-                    {o.type}
-                  </span>{" "}
-                  {o.type === "text" ? o.text.substring(0, 20) : o.fileName || "Image"}
-                </span>
-                <button
-                  className="btn btn-danger btn-sm"
-                  onClick={(e) => {
-                    e.stopPropagation();
-                    onDeleteOverlay(o.id);
-                  }}
-                >
-                  ×
-                </button>
-              </div>
-            ))}
-          {overlays.filter((o) => o.page === currentPage).length === 0 && (
+                ×
+              </button>
+            </div>
+          ))}
+          {pageOverlays.length === 0 && (
             <p style={{ fontSize: 12, color: "#aaa", textAlign: "center", padding: 10 }}>
               No elements on this page
             </p>
@@ -195,14 +827,125 @@ export default function Sidebar({
         </div>
       </div>
 
+      {/* Signature Library */}
+      <SignatureLibrary
+        signatures={signatures}
+        setSignatures={setSignatures}
+        onAddImage={onAddImage}
+      />
+
+      {/* Templates Panel */}
+      <TemplatesPanel overlays={overlays} onApplyTemplate={onApplyTemplate} />
+
+      {/* Mail Merge Panel */}
+      <MailMergePanel
+        onMailMerge={onMailMerge}
+        mergeResults={mergeResults}
+        processing={processing}
+        disabled={overlays.length === 0}
+      />
+
+      {/* Batch Panel */}
+      <BatchPanel
+        onBatch={onBatch}
+        batchResults={batchResults}
+        processing={processing}
+        disabled={overlays.length === 0}
+      />
+
+      {/* Pages Panel */}
+      <PagesPanel
+        totalPages={totalPages}
+        currentPage={currentPage}
+        onInsertToc={onInsertToc}
+        onPoster={onPoster}
+        posterResult={posterResult}
+        onRemoveBlankPages={onRemoveBlankPages}
+        processing={processing}
+      />
+
+      {/* Page Setup Panel */}
+      <PageSetupPanel
+        onNormalizeOrientation={onNormalizeOrientation}
+        onAdjustMargins={onAdjustMargins}
+        onAutoCrop={onAutoCrop}
+        processing={processing}
+      />
+
+      {/* Page Labels Panel */}
+      <PageLabelsPanel
+        pageLabels={pageLabels}
+        onChange={onPageLabelsChange}
+        totalPages={totalPages}
+      />
+
+      {/* Form Data Panel */}
+      {formFieldCount > 0 && (
+        <FormDataPanel
+          fieldCount={formFieldCount}
+          formData={formData}
+          onExport={onExportForm}
+          onImport={onImportForm}
+          onClear={onClearForm}
+        />
+      )}
+
       {/* Actions Panel */}
       <div className="panel">
         <h3>Actions</h3>
         <div style={{ display: "flex", flexDirection: "column", gap: 8 }}>
+          {overlays.some((o) => o.type === "image") && (
+            <div className="form-group">
+              <label>Image Quality</label>
+              <select
+                value={imageQuality}
+                onChange={(e) => onImageQualityChange(e.target.value)}
+              >
+                {Object.entries(IMAGE_QUALITY).map(([key, preset]) => (
+                  <option key={key} value={key}>
+                    {preset.label}
+                  </option>
+                ))}
+              </select>
+            </div>
+          )}
+          <div className="form-group">
+            <label>
+              <input
+                type="checkbox"
+                style={{ width: "auto", marginRight: 6 }}
+                checked={sanitize}
+                onChange={(e) => onSanitizeChange(e.target.checked)}
+              />
+              Remove scripts, auto-run actions and attachments
+            </label>
+          </div>
+          <div className="form-group">
+            <label>
+              <input
+                type="checkbox"
+                style={{ width: "auto", marginRight: 6 }}
+                checked={scrub}
+                onChange={(e) => onScrubChange(e.target.checked)}
+              />
+              Remove author and software metadata
+            </label>
+          </div>
+          <div className="form-group">
+            <label>
+              <input
+                type="checkbox"
+                style={{ width: "auto", marginRight: 6 }}
+                checked={skipFailures}
+                onChange={(e) => onSkipFailuresChange(e.target.checked)}
+              />
+              Skip elements that fail instead of stopping
+            </label>
+          </div>
           <button
             className="btn btn-primary"
             onClick={onProcess}
-            disabled={processing || overlays.length === 0}
+            disabled={processing || !hasEdits}
           >
             {processing ? (
               <>
@@ -213,14 +956,71 @@ export default function Sidebar({
             )}
           </button>
 
+          <button
+            className="btn"
+            onClick={onValidate}
+            disabled={processing || overlays.length === 0}
+          >
+            🔍 Check for Problems
+          </button>
+          <DiagnosticsList
+            diagnostics={diagnostics}
+            overlays={overlays}
+            onSelect={onShowOverlay}
+          />
+
+          {progress && (
+            <div className="progress">
+              <progress value={progress.done} max={progress.total || 1} />
+              <span>{progress.label}</span>
+              <button className="btn btn-sm" onClick={onCancel}>
+                Cancel
+              </button>
+            </div>
+          )}
+
           {downloadUrl && (
             <div className="download-section">
-              <a href={downloadUrl} download>
+              <div className="form-group">
+                <input
+                  type="text"
+                  placeholder={downloadName}
+                  value={customName}
+                  onChange={(e) => setCustomName(e.target.value)}
+                />
+              </div>
+              <a href={downloadUrl} download={downloadFileName(customName, downloadName)}>
                 ⬇️ Download Edited PDF
               </a>
+              <iframe className="pdf-preview" src={downloadUrl} title="Preview" />
+              <a
+                className="preview-link"
+                href={downloadUrl}
+                target="_blank"
+                rel="noopener noreferrer"
+              >
+                👁️ Open in browser viewer
+              </a>
             </div>
           )}
 
+          <button className="btn" onClick={onExportNavigation}>
+            🔗 Export Links & Bookmarks
+          </button>
+
+          <button
+            className="btn"
+            onClick={onSplitByBookmarks}
+            disabled={processing}
+            title="One PDF per top-level bookmark, without the placed elements"
+          >
+            📑 Split by Bookmarks
+          </button>
+          <OutputList
+            results={splitResults}
+            zipName={splitZipName}
+          />
+
           <button className="btn" onClick={onReset}>
             🔄 Upload New PDF
           </button>
@@ -229,3 +1029,96 @@ export default function Sidebar({
     </>
   );
 }
+
+// A user-typed name wins over the default; ".pdf" is added when missing
+function downloadFileName(customName, defaultName) {
+  const name = customName.trim();
+  if (!name) return defaultName;
+  return sanitizeFileName(/\.pdf$/i.test(name) ? name : `${name}.pdf`);
+}
+
+// Height of an overlay as a percentage of the page, or null when it
+// depends on how its text wraps inside an unsized box
+function overlayHeight(overlay, pageHeight) {
+  if (overlay.type === "stamp") return ((overlay.size || 16) / pageHeight) * 100;
+  if (overlay.type !== "text" || overlay.height) return overlay.height || 0;
+  if (overlay.width) return null;
+  const fontSize = overlay.fontSize || 14;
+  const lines = (overlay.text || "").split(/\r\n|\r|\n/).length;
+  const blockHeight = fontSize + (lines - 1) * fontSize * (overlay.lineHeight || 1.2);
+  return (blockHeight / pageHeight) * 100;
+}
+
+/**
+ * Numeric X/Y inputs for the overlay's position in the chosen units, measured
+ * from the chosen page corner to the element's nearest edge.
+ */
+function PositionFields({ overlay, pageSize, units, setUnits, origin, setOrigin, onUpdateOverlay }) {
+  if (!pageSize) return null;
+  const round = (n) => Math.round(n * 100) / 100;
+
+  // From the bottom-left, Y measures up to the element's bottom edge; when
+  // that edge isn't known (wrapped text) it falls back to the top edge
+  const fromBottom = origin === "bottom-left";
+  const height = fromBottom ? overlayHeight(overlay, pageSize.height) : 0;
+  const edge = height ?? 0;
+  const yShown = fromBottom ? 100 - overlay.y - edge : overlay.y;
+  const yLabel = !fromBottom ? "Y" : height === null ? "Y (top edge)" : "Y (bottom edge)";
+
+  const updatePosition = (axis, value) => {
+    const v = parseFloat(value);
+    if (isNaN(v)) return;
+    if (axis === "x") {
+      onUpdateOverlay(overlay.id, { x: toPercent(v, units, pageSize.width) });
+    } else {
+      const percent = toPercent(v, units, pageSize.height);
+      onUpdateOverlay(overlay.id, {
+        y: fromBottom ? 100 - percent - edge : percent,
+      });
+    }
+  };
+
+  return (
+    <>
+      <div className="form-row">
+        <div className="form-group">
+          <label>Units</label>
+          <select value={units} onChange={(e) => setUnits(e.target.value)}>
+            {Object.entries(UNITS).map(([key, u]) => (
+              <option key={key} value={key}>
+                {u.label}
+              </option>
+            ))}
+          </select>
+        </div>
+        <div className="form-group">
+          <label>Origin</label>
+          <select value={origin} onChange={(e) => setOrigin(e.target.value)}>
+            <option value="top-left">Top-left</option>
+            <option value="bottom-left">Bottom-left</option>
+          </select>
+        </div>
+      </div>
+      <div className="form-row">
+        <div className="form-group">
+          <label>X</label>
+          <input
+            type="number"
+            step="any"
+            value={round(fromPercent(overlay.x, units, pageSize.width))}
+            onChange={(e) => updatePosition("x", e.target.value)}
+          />
+        </div>
+        <div className="form-group">
+          <label>{yLabel}</label>
+          <input
+            type="number"
+            step="any"
+            value={round(fromPercent(yShown, units, pageSize.height))}
+            onChange={(e) => updatePosition("y", e.target.value)}
+          />
+        </div>
+      </div>
+    </>
+  );
+}
@@ -0,0 +1,44 @@
+import React from "react";
+import { deleteSignature } from "../signatures";
+
+export default function SignatureLibrary({ signatures, setSignatures, onAddImage }) {
+  return (
+    <div className="panel">
+      <h3>Signature Library</h3>
+      <div className="overlay-list">
+        {signatures.map((s) => (
+          <div
+            key={s.id}
+            className="overlay-item"
+            style={{ cursor: "pointer" }}
+            title="Place on current page"
+            onClick={() => onAddImage(s.imageData, s.name)}
+          >
+            <span style={{ display: "flex", alignItems: "center", gap: 8 }}>
+              <img
+                src={s.imageData}
+                alt={s.name}
+                style={{ height: 24, maxWidth: 80, objectFit: "contain" }}
+              />
+              {s.name}
+            </span>
+            <button
+              className="btn btn-danger btn-sm"
+              onClick={(e) => {
+                e.stopPropagation();
+                setSignatures(deleteSignature(s.id));
+              }}
+            >
+              ×
+            </button>
+          </div>
+        ))}
+        {signatures.length === 0 && (
+          <p style={{ fontSize: 12, color: "#aaa", textAlign: "center", padding: 10 }}>
+            Select an image and choose "Save to library" to reuse it
+          </p>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,76 @@
+import React, { useState } from "react";
+import { loadTemplates, saveTemplate, deleteTemplate } from "../templates";
+
+export default function TemplatesPanel({ overlays, onApplyTemplate }) {
+  const [templates, setTemplates] = useState(loadTemplates);
+  const [name, setName] = useState("");
+  const [error, setError] = useState(null);
+
+  const handleSave = () => {
+    const trimmed = name.trim();
+    if (!trimmed || overlays.length === 0) return;
+    try {
+      setTemplates(saveTemplate(trimmed, overlays));
+      setName("");
+      setError(null);
+    } catch (e) {
+      setError(e.message);
+    }
+  };
+
+  return (
+    <div className="panel">
+      <h3>Templates</h3>
+      <form
+        className="form-row"
+        onSubmit={(e) => {
+          e.preventDefault();
+          handleSave();
+        }}
+      >
+        <div className="form-group">
+          <input
+            type="text"
+            placeholder="Template name"
+            value={name}
+            onChange={(e) => setName(e.target.value)}
+          />
+        </div>
+        <button
+          type="submit"
+          className="btn btn-sm"
+          style={{ height: 34 }}
+          disabled={!name.trim() || overlays.length === 0}
+        >
+          💾 Save
+        </button>
+      </form>
+      {error && <p style={{ fontSize: 12, color: "#c0392b" }}>{error}</p>}
+      <div className="overlay-list">
+        {templates.map((t) => (
+          <div key={t.id} className="overlay-item">
+            <span>
+              {t.name} ({t.overlays.length})
+            </span>
+            <span style={{ display: "flex", gap: 4 }}>
+              <button className="btn btn-sm" onClick={() => onApplyTemplate(t)}>
+                Apply
+              </button>
+              <button
+                className="btn btn-danger btn-sm"
+                onClick={() => setTemplates(deleteTemplate(t.id))}
+              >
+                ×
+              </button>
+            </span>
+          </div>
+        ))}
+        {templates.length === 0 && (
+          <p style={{ fontSize: 12, color: "#aaa", textAlign: "center", padding: 10 }}>
+            Save the current layout to reuse it on other documents
+          </p>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,104 @@
+import React, { useState } from "react";
+import { createPdfFromText, PAGE_SIZES } from "../textToPdf";
+import { FONT_FAMILIES } from "../fonts";
+
+// Symbol and ZapfDingbats can't set ordinary text
+const TEXT_FAMILIES = ["Helvetica", "Times", "Courier"];
+
+export default function TextToPdfForm({ onCreate, onError }) {
+  const [text, setText] = useState("");
+  const [markdown, setMarkdown] = useState(true);
+  const [pageSize, setPageSize] = useState("A4");
+  const [marginMm, setMarginMm] = useState(20);
+  const [fontFamily, setFontFamily] = useState("Helvetica");
+  const [fontSize, setFontSize] = useState(11);
+  const [creating, setCreating] = useState(false);
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    setCreating(true);
+    try {
+      const bytes = await createPdfFromText(text, {
+        pageSize,
+        marginMm,
+        fontFamily,
+        fontSize,
+        markdown,
+      });
+      onCreate(new File([bytes], "document.pdf", { type: "application/pdf" }));
+    } catch (err) {
+      onError?.(`Could not create the PDF: ${err.message}`);
+    } finally {
+      setCreating(false);
+    }
+  };
+
+  return (
+    <form className="panel" onSubmit={handleSubmit}>
+      <div className="form-group">
+        <textarea
+          rows={10}
+          placeholder={"# Notice\n\nWrite plain text or Markdown here..."}
+          value={text}
+          onChange={(e) => setText(e.target.value)}
+        />
+      </div>
+      <div className="form-row">
+        <div className="form-group">
+          <label>Page Size</label>
+          <select value={pageSize} onChange={(e) => setPageSize(e.target.value)}>
+            {Object.entries(PAGE_SIZES).map(([key, { label }]) => (
+              <option key={key} value={key}>
+                {label}
+              </option>
+            ))}
+          </select>
+        </div>
+        <div className="form-group">
+          <label>Margins (mm)</label>
+          <input
+            type="number"
+            min={0}
+            max={80}
+            value={marginMm}
+            onChange={(e) => setMarginMm(Math.min(80, Math.max(0, parseInt(e.target.value) || 0)))}
+          />
+        </div>
+        <div className="form-group">
+          <label>Font</label>
+          <select value={fontFamily} onChange={(e) => setFontFamily(e.target.value)}>
+            {TEXT_FAMILIES.map((key) => (
+              <option key={key} value={key}>
+                {FONT_FAMILIES[key].label}
+              </option>
+            ))}
+          </select>
+        </div>
+        <div className="form-group">
+          <label>Size</label>
+          <input
+            type="number"
+            min={6}
+            max={36}
+            value={fontSize}
+            onChange={(e) => setFontSize(parseInt(e.target.value) || 11)}
+          />
+        </div>
+      </div>
+      <div className="form-group">
+        <label>
+          <input
+            type="checkbox"
+            style={{ width: "auto", marginRight: 6 }}
+            checked={markdown}
+            onChange={(e) => setMarkdown(e.target.checked)}
+          />
+          Format as Markdown (headings, lists, paragraphs)
+        </label>
+      </div>
+      <button type="submit" className="btn btn-primary" disabled={creating || !text.trim()}>
+        {creating ? "Creating..." : "📝 Create PDF"}
+      </button>
+    </form>
+  );
+}
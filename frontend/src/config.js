@@ -0,0 +1,17 @@
+/**
+ * Build-time settings, overridable through Vite env variables
+ * (e.g. VITE_MAX_PDF_SIZE_MB=250 npm run build).
+ */
+function envNumber(name, fallback) {
+  const value = Number(import.meta.env[name]);
+  return value > 0 ? value : fallback;
+}
+
+// Largest PDF the editor will open, in megabytes
+export const MAX_PDF_SIZE_MB = envNumber("VITE_MAX_PDF_SIZE_MB", 100);
+
+// Largest image or stamp PDF accepted as an overlay, in megabytes
+export const MAX_OVERLAY_SIZE_MB = envNumber("VITE_MAX_OVERLAY_SIZE_MB", 10);
+
+// Longest a single document may take to generate before it's stopped, in seconds
+export const GENERATION_TIMEOUT_S = envNumber("VITE_GENERATION_TIMEOUT_S", 300);
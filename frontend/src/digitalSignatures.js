@@ -0,0 +1,44 @@
+import { PDFDocument, PDFName, PDFDict, PDFArray } from "pdf-lib";
+
+/**
+ * Count the signed signature fields in a loaded document. Any such PDF
+ * loses its signatures' validity when pdf-lib rewrites it, so callers warn
+ * before generating. Fields are walked through /Kids, and /FT is inherited
+ * from parent fields as the spec allows.
+ */
+export function countDigitalSignatures(pdfDoc) {
+  const acroForm = pdfDoc.catalog.lookup(PDFName.of("AcroForm"));
+  if (!(acroForm instanceof PDFDict)) return 0;
+
+  const seen = new Set();
+  const visit = (fields, inheritedType) => {
+    if (!(fields instanceof PDFArray)) return 0;
+    let count = 0;
+    for (const ref of fields.asArray()) {
+      const field = pdfDoc.context.lookup(ref);
+      if (!(field instanceof PDFDict) || seen.has(field)) continue;
+      seen.add(field);
+
+      const type = field.lookup(PDFName.of("FT")) || inheritedType;
+      if (type === PDFName.of("Sig") && field.lookup(PDFName.of("V")) instanceof PDFDict) {
+        count++;
+      }
+      count += visit(field.lookup(PDFName.of("Kids")), type);
+    }
+    return count;
+  };
+  return visit(acroForm.lookup(PDFName.of("Fields")));
+}
+
+/**
+ * Load a file only to count its signatures. Files that can't be parsed
+ * count as unsigned; processing them reports the real error.
+ */
+export async function countFileSignatures(file) {
+  try {
+    const doc = await PDFDocument.load(await file.arrayBuffer(), { updateMetadata: false });
+    return countDigitalSignatures(doc);
+  } catch (e) {
+    return 0;
+  }
+}
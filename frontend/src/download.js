@@ -0,0 +1,12 @@
+/**
+ * Save a Blob through a temporary link. The object URL is revoked shortly
+ * after, once the browser has started the download.
+ */
+export function downloadBlob(blob, fileName) {
+  const url = URL.createObjectURL(blob);
+  const link = document.createElement("a");
+  link.href = url;
+  link.download = fileName;
+  link.click();
+  setTimeout(() => URL.revokeObjectURL(url), 1000);
+}
@@ -0,0 +1,33 @@
+import { overlayReference } from "./overlayLabels";
+
+/**
+ * An error from PDF generation with a stable, machine-readable code such as
+ * "PDF_ENCRYPTED" or "IMAGE_DECODE_FAILED" (the same codes validation.js
+ * reports). Errors caused by one overlay carry its position in
+ * details.index, plus details.page when it happened on a specific page.
+ */
+export class PdfEditError extends Error {
+  constructor(code, message, details = {}) {
+    super(message);
+    this.name = "PdfEditError";
+    this.code = code;
+    this.details = details;
+  }
+}
+
+// pdf-lib reports characters a standard font can't draw as
+// "WinAnsi cannot encode ..."
+export function isEncodingError(err) {
+  return /cannot encode/i.test(err?.message || "");
+}
+
+/**
+ * A user-facing message, prefixed with where to find the failing element
+ * in the editor (see overlayReference) when it is one of `overlays`.
+ */
+export function describeError(err, overlays = []) {
+  const { index, page } = err?.details || {};
+  const overlay = overlays[index];
+  if (!overlay) return err.message;
+  return `${overlayReference(overlay, page)}: ${err.message}`;
+}
@@ -0,0 +1,67 @@
+const FETCH_TIMEOUT_MS = 30000;
+
+/**
+ * Download an https:// URL into a File, enforcing a size limit and a 30s
+ * timeout. The browser does the fetching, so the host has to allow
+ * cross-origin requests. Errors carry a message fit to show the user.
+ */
+export async function fetchFile(input, { maxSizeMB, fallbackName, fallbackType }) {
+  let url;
+  try {
+    url = new URL(String(input).trim());
+  } catch (err) {
+    throw new Error(`Enter a full URL, e.g. https://example.com/${fallbackName}`);
+  }
+  if (url.protocol !== "https:") {
+    throw new Error("Only https:// URLs are supported");
+  }
+
+  const controller = new AbortController();
+  const timer = setTimeout(() => controller.abort(), FETCH_TIMEOUT_MS);
+  try {
+    const res = await fetch(url.href, { signal: controller.signal });
+    if (!res.ok) throw new Error(`Download failed: the server responded with ${res.status}`);
+
+    // Checked up front when the server says, then counted while reading,
+    // since chunked responses don't declare a length
+    const limit = maxSizeMB * 1024 * 1024;
+    const limitError = new Error(`Download failed: the file is larger than the ${maxSizeMB}MB limit`);
+    if (Number(res.headers.get("Content-Length")) > limit) throw limitError;
+
+    const chunks = [];
+    let received = 0;
+    const reader = res.body?.getReader();
+    while (reader) {
+      const { done, value } = await reader.read();
+      if (done) break;
+      received += value.length;
+      if (received > limit) {
+        controller.abort();
+        throw limitError;
+      }
+      chunks.push(value);
+    }
+
+    const type = (res.headers.get("Content-Type") || "").split(";")[0].trim();
+    return new File(chunks, fileNameFromUrl(url) || fallbackName, { type: type || fallbackType });
+  } catch (err) {
+    if (err.name === "AbortError") throw new Error("Download failed: timed out");
+    // fetch() reports network and CORS failures the same way
+    if (err instanceof TypeError) {
+      throw new Error("Download failed: the server may not allow access from this page");
+    }
+    throw err;
+  } finally {
+    clearTimeout(timer);
+  }
+}
+
+// The last path segment, percent-decoded; malformed escapes fall back to
+// the caller's default name rather than surfacing a URIError
+function fileNameFromUrl(url) {
+  try {
+    return decodeURIComponent(url.pathname.split("/").pop() || "");
+  } catch (err) {
+    return "";
+  }
+}
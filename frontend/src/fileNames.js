@@ -0,0 +1,16 @@
+/**
+ * Replace characters that are unsafe or awkward in downloaded file names.
+ * Letters and digits in any script are kept, so "Résumé" stays as it is.
+ */
+export function sanitizeFileName(name) {
+  return name.replace(/[^\p{L}\p{M}\p{N} ._-]+/gu, "_").trim().replace(/^[._]+/, "") || "document";
+}
+
+/**
+ * Name for an edited copy of `originalName`, e.g. "Contract v2.pdf" becomes
+ * "Contract v2-edited.pdf".
+ */
+export function editedFileName(originalName, suffix = "edited") {
+  const stem = sanitizeFileName((originalName || "document").replace(/\.pdf$/i, ""));
+  return `${stem}-${suffix}.pdf`;
+}
@@ -0,0 +1,15 @@
+import { test } from "node:test";
+import assert from "node:assert/strict";
+import { editedFileName, sanitizeFileName } from "./fileNames.js";
+
+test("keeps accented and non-Latin names", () => {
+  assert.equal(editedFileName("Résumé.pdf"), "Résumé-edited.pdf");
+  assert.equal(editedFileName("Приложение.pdf"), "Приложение-edited.pdf");
+  assert.equal(sanitizeFileName("Müller"), "Müller");
+});
+
+test("replaces characters that aren't allowed in file names", () => {
+  assert.equal(sanitizeFileName('a/b\\c:d*e?"f<g>h|i'), "a_b_c_d_e_f_g_h_i");
+  assert.equal(sanitizeFileName("../secret"), "secret");
+  assert.equal(sanitizeFileName("///"), "document");
+});
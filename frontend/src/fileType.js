@@ -0,0 +1,47 @@
+import { MAX_PDF_SIZE_MB } from "./config";
+
+/**
+ * Identify an uploaded file by its leading bytes rather than its name or the
+ * browser-reported MIME type (both come from the file extension).
+ */
+const EXECUTABLE_SIGNATURES = [
+  [0x4d, 0x5a], // MZ: Windows PE / DOS executables
+  [0x7f, 0x45, 0x4c, 0x46], // \x7fELF: Linux executables
+  [0xfe, 0xed, 0xfa, 0xce], // Mach-O 32-bit
+  [0xfe, 0xed, 0xfa, 0xcf], // Mach-O 64-bit
+  [0xce, 0xfa, 0xed, 0xfe], // Mach-O 32-bit, little-endian
+  [0xcf, 0xfa, 0xed, 0xfe], // Mach-O 64-bit, little-endian
+  [0x23, 0x21], // #!: shell scripts
+];
+
+const startsWith = (bytes, signature) => signature.every((b, i) => bytes[i] === b);
+
+/**
+ * Returns "pdf", "executable" or "unknown". PDF readers accept a %PDF-
+ * header anywhere in the first 1024 bytes, so this does too.
+ */
+export async function sniffFileType(file) {
+  const bytes = new Uint8Array(await file.slice(0, 1024).arrayBuffer());
+  if (EXECUTABLE_SIGNATURES.some((sig) => startsWith(bytes, sig))) return "executable";
+
+  const header = String.fromCharCode(...bytes);
+  if (header.includes("%PDF-")) return "pdf";
+  return "unknown";
+}
+
+/**
+ * The checks every PDF goes through before it's read: the size limit
+ * first, so huge files never get loaded into memory, then the content
+ * sniff. Returns a message explaining why the file is refused, or null.
+ */
+export async function checkPdfFile(file) {
+  if (file.size > MAX_PDF_SIZE_MB * 1024 * 1024) {
+    return `${file.name} is ${(file.size / 1024 / 1024).toFixed(1)}MB; the limit is ${MAX_PDF_SIZE_MB}MB`;
+  }
+  // Trust the content, not the extension: renamed PDFs are accepted and
+  // disguised executables are refused
+  const kind = await sniffFileType(file);
+  if (kind === "executable") return `${file.name} is an executable program, not a PDF`;
+  if (kind !== "pdf") return `${file.name} is not a PDF file`;
+  return null;
+}
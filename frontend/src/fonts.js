@@ -0,0 +1,62 @@
+/**
+ * Font families available for text overlays.
+ *
+ * `variants` maps a style (regular, bold, italic, boldItalic) to the name of
+ * one of the 14 standard PDF fonts, which pdf-lib can embed without any font
+ * file. `css` is the closest browser font stack for the editor preview.
+ */
+export const FONT_FAMILIES = {
+  Helvetica: {
+    label: "Helvetica",
+    css: "Helvetica, Arial, sans-serif",
+    variants: {
+      regular: "Helvetica",
+      bold: "Helvetica-Bold",
+      italic: "Helvetica-Oblique",
+      boldItalic: "Helvetica-BoldOblique",
+    },
+  },
+  Times: {
+    label: "Times Roman",
+    css: "\"Times New Roman\", Times, serif",
+    variants: {
+      regular: "Times-Roman",
+      bold: "Times-Bold",
+      italic: "Times-Italic",
+      boldItalic: "Times-BoldItalic",
+    },
+  },
+  Courier: {
+    label: "Courier",
+    css: "\"Courier New\", Courier, monospace",
+    variants: {
+      regular: "Courier",
+      bold: "Courier-Bold",
+      italic: "Courier-Oblique",
+      boldItalic: "Courier-BoldOblique",
+    },
+  },
+  Symbol: {
+    label: "Symbol",
+    css: "Symbol, serif",
+    variants: { regular: "Symbol" },
+  },
+  ZapfDingbats: {
+    label: "Zapf Dingbats",
+    css: "\"Zapf Dingbats\", serif",
+    variants: { regular: "ZapfDingbats" },
+  },
+};
+
+/**
+ * Resolve a text overlay's family/bold/italic to a standard PDF font name.
+ * Falls back to the regular face when a family has no matching variant.
+ */
+export function standardFontName(overlay) {
+  const family = FONT_FAMILIES[overlay.fontFamily] || FONT_FAMILIES.Helvetica;
+  let style = "regular";
+  if (overlay.bold && overlay.italic) style = "boldItalic";
+  else if (overlay.bold) style = "bold";
+  else if (overlay.italic) style = "italic";
+  return family.variants[style] || family.variants.regular;
+}
@@ -0,0 +1,139 @@
+import {
+  PDFTextField,
+  PDFCheckBox,
+  PDFDropdown,
+  PDFOptionList,
+  PDFRadioGroup,
+} from "pdf-lib";
+
+/**
+ * Form field values as a plain { fullFieldName: value } object: strings for
+ * text fields and radio groups, booleans for checkboxes, and arrays for
+ * dropdowns and list boxes. Buttons and signature fields carry no value
+ * and are left out.
+ */
+export function exportFormData(pdfDoc) {
+  const values = {};
+  for (const field of pdfDoc.getForm().getFields()) {
+    const name = field.getName();
+    if (field instanceof PDFTextField) values[name] = field.getText() || "";
+    else if (field instanceof PDFCheckBox) values[name] = field.isChecked();
+    else if (field instanceof PDFRadioGroup) values[name] = field.getSelected() || "";
+    else if (field instanceof PDFDropdown || field instanceof PDFOptionList) {
+      values[name] = field.getSelected();
+    }
+  }
+  return values;
+}
+
+/**
+ * Fill form fields from a values object as produced by exportFormData or
+ * parseFormData. Returns the names that couldn't be applied (no such field,
+ * or a value the field doesn't accept) so callers can report them.
+ */
+export function fillForm(pdfDoc, values) {
+  const form = pdfDoc.getForm();
+  const skipped = [];
+  for (const [name, value] of Object.entries(values)) {
+    const field = form.getFieldMaybe(name);
+    try {
+      if (field instanceof PDFTextField) field.setText(String(value ?? ""));
+      else if (field instanceof PDFCheckBox) isChecked(value) ? field.check() : field.uncheck();
+      else if (field instanceof PDFRadioGroup) {
+        if (value) field.select(String(value));
+        else field.clear();
+      } else if (field instanceof PDFDropdown || field instanceof PDFOptionList) {
+        const options = [].concat(value).filter(Boolean).map(String);
+        if (options.length) field.select(options);
+        else field.clear();
+      } else {
+        skipped.push(name);
+      }
+    } catch (e) {
+      skipped.push(name);
+    }
+  }
+  return skipped;
+}
+
+// XFDF carries checkbox states as export values such as "Yes" / "Off"
+function isChecked(value) {
+  if (typeof value === "boolean") return value;
+  return !["", "off", "false", "0", "no"].includes(String(value).trim().toLowerCase());
+}
+
+const escapeXml = (s) =>
+  String(s).replace(/[<>&"']/g, (c) => `&#${c.charCodeAt(0)};`);
+
+/**
+ * Serialize values as XFDF. Dotted field names become nested <field>
+ * elements, as the XFDF spec describes hierarchical names.
+ */
+export function toXfdf(values, pdfFileName) {
+  const tree = {};
+  for (const [name, value] of Object.entries(values)) {
+    let node = tree;
+    for (const part of name.split(".")) {
+      node.kids = node.kids || {};
+      node = node.kids[part] = node.kids[part] || {};
+    }
+    node.value = value;
+  }
+
+  const render = (kids, indent) =>
+    Object.entries(kids || {})
+      .map(([part, node]) => {
+        const inner = [];
+        if ("value" in node) {
+          const value = typeof node.value === "boolean" ? (node.value ? "Yes" : "Off") : node.value;
+          for (const v of [].concat(value)) inner.push(`${indent}  <value>${escapeXml(v)}</value>`);
+        }
+        if (node.kids) inner.push(render(node.kids, indent + "  "));
+        return `${indent}<field name="${escapeXml(part)}">\n${inner.join("\n")}\n${indent}</field>`;
+      })
+      .join("\n");
+
+  return [
+    '<?xml version="1.0" encoding="UTF-8"?>',
+    '<xfdf xmlns="http://ns.adobe.com/xfdf/" xml:space="preserve">',
+    `  <f href="${escapeXml(pdfFileName || "")}"/>`,
+    "  <fields>",
+    render(tree.kids, "    "),
+    "  </fields>",
+    "</xfdf>",
+    "",
+  ].join("\n");
+}
+
+/**
+ * Read form values from a JSON object ({ name: value }) or an XFDF file,
+ * chosen by extension and falling back to sniffing the content.
+ */
+export function parseFormData(text, fileName = "") {
+  const trimmed = text.trim();
+  if (/\.json$/i.test(fileName) || trimmed.startsWith("{")) {
+    const values = JSON.parse(trimmed);
+    if (!values || typeof values !== "object" || Array.isArray(values)) {
+      throw new Error("Expected a JSON object of field names to values");
+    }
+    return values;
+  }
+
+  const doc = new DOMParser().parseFromString(trimmed, "application/xml");
+  if (doc.getElementsByTagName("parsererror").length) throw new Error("Not valid XFDF");
+
+  const values = {};
+  const walk = (parent, prefix) => {
+    for (const el of parent.children) {
+      if (el.localName !== "field") continue;
+      const name = prefix + el.getAttribute("name");
+      const own = [...el.children].filter((c) => c.localName === "value").map((c) => c.textContent);
+      if (own.length) values[name] = own.length === 1 ? own[0] : own;
+      walk(el, `${name}.`);
+    }
+  };
+  const fields = doc.getElementsByTagNameNS("*", "fields")[0];
+  if (!fields) throw new Error("No <fields> element found");
+  walk(fields, "");
+  return values;
+}
@@ -0,0 +1,12 @@
+/**
+ * Output resolution presets for image overlays. Images are downsampled to
+ * at most `dpi` at the size they're placed on the page; `jpegQuality`
+ * additionally re-encodes opaque images as JPEG (transparent ones stay PNG
+ * so their alpha channel survives).
+ */
+export const IMAGE_QUALITY = {
+  original: { label: "Original resolution", dpi: 0 },
+  print: { label: "Print (300 DPI)", dpi: 300 },
+  screen: { label: "Screen (150 DPI)", dpi: 150 },
+  small: { label: "Smallest file (96 DPI, JPEG)", dpi: 96, jpegQuality: 0.8 },
+};
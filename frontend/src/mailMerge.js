@@ -0,0 +1,88 @@
+import { editedFileName, sanitizeFileName } from "./fileNames";
+
+/**
+ * Parse CSV text (RFC 4180: quoted fields, "" escapes, embedded newlines)
+ * into an array of records keyed by the header row.
+ */
+export function parseCsv(text) {
+  const rows = [];
+  let row = [];
+  let field = "";
+  let quoted = false;
+
+  for (let i = 0; i < text.length; i++) {
+    const c = text[i];
+    if (quoted) {
+      if (c === '"' && text[i + 1] === '"') {
+        field += '"';
+        i++;
+      } else if (c === '"') {
+        quoted = false;
+      } else {
+        field += c;
+      }
+    } else if (c === '"') {
+      quoted = true;
+    } else if (c === ",") {
+      row.push(field);
+      field = "";
+    } else if (c === "\n" || c === "\r") {
+      if (c === "\r" && text[i + 1] === "\n") i++;
+      row.push(field);
+      rows.push(row);
+      row = [];
+      field = "";
+    } else {
+      field += c;
+    }
+  }
+  if (field || row.length) {
+    row.push(field);
+    rows.push(row);
+  }
+
+  const nonEmpty = rows.filter((r) => r.some((f) => f.trim()));
+  if (nonEmpty.length === 0) return [];
+  const header = nonEmpty[0].map((h) => h.trim());
+  return nonEmpty.slice(1).map((r) =>
+    Object.fromEntries(header.map((h, i) => [h, r[i] ?? ""]))
+  );
+}
+
+/**
+ * Parse a mail-merge data file: a JSON array of objects, or CSV with a
+ * header row. Throws with a readable message if neither fits.
+ */
+export function parseRecords(text, fileName = "") {
+  const trimmed = text.trim();
+  if (fileName.toLowerCase().endsWith(".json") || trimmed.startsWith("[")) {
+    const data = JSON.parse(trimmed);
+    if (!Array.isArray(data) || data.some((r) => typeof r !== "object" || r === null)) {
+      throw new Error("JSON data must be an array of objects");
+    }
+    return data;
+  }
+  return parseCsv(text);
+}
+
+/**
+ * Split a record's field names into those usable as {field} placeholders
+ * and those that aren't: empty headers, or names containing braces, which
+ * the placeholder syntax can't express.
+ */
+export function placeholderFields(record) {
+  const usable = [];
+  const unusable = [];
+  for (const name of Object.keys(record || {})) {
+    (name && !/[{}]/.test(name) ? usable : unusable).push(name);
+  }
+  return { usable, unusable };
+}
+
+/**
+ * Build a safe, unique-ish output file name for a merged record.
+ */
+export function mergeFileName(baseName, record, nameField, index) {
+  const label = nameField && record[nameField] ? String(record[nameField]) : String(index + 1);
+  return editedFileName(baseName, sanitizeFileName(label));
+}
@@ -0,0 +1,67 @@
+import { test } from "node:test";
+import assert from "node:assert/strict";
+import { mergeFileName, parseCsv, parseRecords, placeholderFields } from "./mailMerge.js";
+
+test("parses a header row and records", () => {
+  assert.deepEqual(parseCsv("name,city\nAda,London\nAlan,Manchester\n"), [
+    { name: "Ada", city: "London" },
+    { name: "Alan", city: "Manchester" },
+  ]);
+});
+
+test("handles quoted fields, escaped quotes and embedded newlines", () => {
+  const csv = 'name,note\n"Smith, Jo","She said ""hi""\nthen left"\n';
+  assert.deepEqual(parseCsv(csv), [{ name: "Smith, Jo", note: 'She said "hi"\nthen left' }]);
+});
+
+test("accepts CRLF, lone CR and a missing final newline", () => {
+  const expected = [
+    { a: "1", b: "2" },
+    { a: "3", b: "4" },
+  ];
+  assert.deepEqual(parseCsv("a,b\r\n1,2\r\n3,4\r\n"), expected);
+  assert.deepEqual(parseCsv("a,b\r1,2\r3,4"), expected);
+});
+
+test("skips blank lines and trims header names", () => {
+  assert.deepEqual(parseCsv("\n name , age \n\n,\nBo,7\n\n"), [{ name: "Bo", age: "7" }]);
+});
+
+test("pads short rows and drops extra fields", () => {
+  assert.deepEqual(parseCsv("a,b,c\n1\n1,2,3,4"), [
+    { a: "1", b: "", c: "" },
+    { a: "1", b: "2", c: "3" },
+  ]);
+});
+
+test("returns no records for empty or header-only input", () => {
+  assert.deepEqual(parseCsv(""), []);
+  assert.deepEqual(parseCsv("\n\n  \n"), []);
+  assert.deepEqual(parseCsv("a,b\n"), []);
+});
+
+test("keeps the rest of the file when a quote is never closed", () => {
+  assert.deepEqual(parseCsv('a,b\n1,"open\n2,3'), [{ a: "1", b: "open\n2,3" }]);
+});
+
+test("reads JSON arrays and rejects other JSON", () => {
+  assert.deepEqual(parseRecords('[{"a": 1}]'), [{ a: 1 }]);
+  assert.deepEqual(parseRecords("a\n1", "data.csv"), [{ a: "1" }]);
+  assert.throws(() => parseRecords('{"a": 1}', "data.json"), /array of objects/);
+  assert.throws(() => parseRecords("[1, 2]"), /array of objects/);
+  assert.throws(() => parseRecords("[oops"), SyntaxError);
+});
+
+test("separates field names that can't be placeholders", () => {
+  assert.deepEqual(placeholderFields({ name: 1, "": 2, "{x}": 3, "a}b": 4 }), {
+    usable: ["name"],
+    unusable: ["", "{x}", "a}b"],
+  });
+  assert.deepEqual(placeholderFields(null), { usable: [], unusable: [] });
+});
+
+test("names merged files after a field or the record number", () => {
+  assert.equal(mergeFileName("Letter.pdf", { name: "Zoë/Smith" }, "name", 0), "Letter-Zoë_Smith.pdf");
+  assert.equal(mergeFileName("Letter.pdf", { name: "" }, "name", 4), "Letter-5.pdf");
+  assert.equal(mergeFileName("Letter.pdf", { name: "Bo" }, "", 1), "Letter-2.pdf");
+});
@@ -0,0 +1,83 @@
+import * as pdfjsLib from "pdfjs-dist";
+
+function openDocument(pdfBytes, password) {
+  // pdf.js takes ownership of (detaches) the buffer it's given, so pass a copy
+  return pdfjsLib.getDocument({
+    data: new Uint8Array(pdfBytes.slice(0)),
+    password: password || undefined,
+  }).promise;
+}
+
+// Where a link annotation or bookmark of `doc` leads: { url } or a 1-based
+// { destPage }, null when the destination doesn't resolve to a page
+function targetResolver(doc) {
+  const destPage = async (dest) => {
+    try {
+      const explicit = typeof dest === "string" ? await doc.getDestination(dest) : dest;
+      if (!Array.isArray(explicit)) return null;
+      const [target] = explicit;
+      return Number.isInteger(target) ? target + 1 : (await doc.getPageIndex(target)) + 1;
+    } catch (e) {
+      return null;
+    }
+  };
+
+  return async (item) => {
+    const url = item.url || item.unsafeUrl;
+    if (url) return { url };
+    return { destPage: item.dest ? await destPage(item.dest) : null };
+  };
+}
+
+async function mapOutline(items, target) {
+  return Promise.all(
+    (items || []).map(async (item) => ({
+      title: item.title,
+      ...(await target(item)),
+      children: await mapOutline(item.items, target),
+    }))
+  );
+}
+
+/**
+ * The bookmark tree of a document, as plain JSON:
+ *
+ *   [{ title, url | destPage, children: [...] }]
+ *
+ * Destinations that can't be resolved to a page are reported with
+ * destPage: null.
+ */
+export async function extractOutline(pdfBytes, password) {
+  const doc = await openDocument(pdfBytes, password);
+  try {
+    return await mapOutline(await doc.getOutline(), targetResolver(doc));
+  } finally {
+    doc.destroy();
+  }
+}
+
+/**
+ * Link annotations and the bookmark tree of a document, as plain JSON:
+ *
+ *   links:   [{ page, rect: [x1, y1, x2, y2], url } | { page, rect, destPage }]
+ *   outline: as extractOutline returns it
+ *
+ * Rects are in PDF points from the page's bottom-left corner.
+ */
+export async function extractNavigation(pdfBytes, password) {
+  const doc = await openDocument(pdfBytes, password);
+  const target = targetResolver(doc);
+  try {
+    const links = [];
+    for (let page = 1; page <= doc.numPages; page++) {
+      const annotations = await (await doc.getPage(page)).getAnnotations();
+      for (const a of annotations) {
+        if (a.subtype !== "Link") continue;
+        links.push({ page, rect: a.rect.map((n) => Math.round(n * 100) / 100), ...(await target(a)) });
+      }
+    }
+    return { links, outline: await mapOutline(await doc.getOutline(), target) };
+  } finally {
+    doc.destroy();
+  }
+}
@@ -0,0 +1,22 @@
+import { STAMP_GLYPHS } from "./stamps";
+
+/**
+ * A short name for an overlay, as the element list shows it: the start of
+ * its text, its file name, its size (highlights) or its glyph (stamps).
+ */
+export function overlayLabel(o) {
+  if (o.type === "text") return o.text.substring(0, 20);
+  if (o.type === "highlight") return `${o.width}% × ${o.height}%`;
+  if (o.type === "stamp") return (STAMP_GLYPHS[o.glyph] || STAMP_GLYPHS.check).label;
+  return o.fileName || "Image";
+}
+
+/**
+ * Where to find an overlay in the editor, e.g. `Page 3, text "Paid"`. The
+ * sidebar lists elements per page, so problems name the page (`page` when
+ * known, otherwise the overlay's own page selection) rather than a number.
+ */
+export function overlayReference(o, page) {
+  const where = page ? `Page ${page}` : o.pages ? `Pages ${o.pages}` : `Page ${o.page}`;
+  return `${where}, ${o.type} "${overlayLabel(o)}"`;
+}
@@ -0,0 +1,86 @@
+import * as pdfjsLib from "pdfjs-dist";
+
+// Pixels lighter than this on every channel count as paper
+const WHITE_THRESHOLD = 245;
+
+/**
+ * Measure the ink on every page by rendering it and scanning for non-white
+ * pixels. Returns one { box, coverage } per page: box is
+ * [x1, y1, x2, y2] in PDF user space (the same space as the page's
+ * MediaBox), or null for blank pages, and coverage is the fraction (0-1)
+ * of the page's pixels that are inked. Rendering at `scale` 1 means one
+ * pixel per point, which is plenty for cropping and blank detection.
+ */
+export async function detectPageInk(pdfBytes, password, { scale = 1 } = {}) {
+  // pdf.js takes ownership of (detaches) the buffer it's given, so pass a copy
+  const doc = await pdfjsLib.getDocument({
+    data: new Uint8Array(pdfBytes.slice(0)),
+    password: password || undefined,
+  }).promise;
+
+  try {
+    const pages = [];
+    for (let n = 1; n <= doc.numPages; n++) {
+      const page = await doc.getPage(n);
+      const viewport = page.getViewport({ scale });
+      const canvas = document.createElement("canvas");
+      canvas.width = Math.ceil(viewport.width);
+      canvas.height = Math.ceil(viewport.height);
+      const ctx = canvas.getContext("2d", { willReadFrequently: true });
+      ctx.fillStyle = "#ffffff";
+      ctx.fillRect(0, 0, canvas.width, canvas.height);
+      await page.render({ canvasContext: ctx, viewport }).promise;
+
+      const { bounds, coverage } = scanInk(ctx.getImageData(0, 0, canvas.width, canvas.height));
+      if (!bounds) {
+        pages.push({ box: null, coverage });
+        continue;
+      }
+      // Map opposite pixel corners back through the viewport, which also
+      // undoes any /Rotate
+      const [ax, ay] = viewport.convertToPdfPoint(bounds.left, bounds.top);
+      const [bx, by] = viewport.convertToPdfPoint(bounds.right + 1, bounds.bottom + 1);
+      const box = [Math.min(ax, bx), Math.min(ay, by), Math.max(ax, bx), Math.max(ay, by)];
+      pages.push({ box, coverage });
+    }
+    return pages;
+  } finally {
+    doc.destroy();
+  }
+}
+
+/**
+ * The inked box of every page, as detectPageInk() finds it, or null for
+ * blank pages.
+ */
+export async function detectContentBoxes(pdfBytes, password, options) {
+  return (await detectPageInk(pdfBytes, password, options)).map((page) => page.box);
+}
+
+function scanInk({ data, width, height }) {
+  let top = height;
+  let bottom = -1;
+  let left = width;
+  let right = -1;
+  let inked = 0;
+  for (let y = 0; y < height; y++) {
+    for (let x = 0; x < width; x++) {
+      const i = (y * width + x) * 4;
+      if (
+        data[i] < WHITE_THRESHOLD ||
+        data[i + 1] < WHITE_THRESHOLD ||
+        data[i + 2] < WHITE_THRESHOLD
+      ) {
+        inked++;
+        if (y < top) top = y;
+        if (y > bottom) bottom = y;
+        if (x < left) left = x;
+        if (x > right) right = x;
+      }
+    }
+  }
+  return {
+    bounds: bottom < 0 ? null : { top, bottom, left, right },
+    coverage: width * height ? inked / (width * height) : 0,
+  };
+}
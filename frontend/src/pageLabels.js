@@ -0,0 +1,43 @@
+import { PDFName, PDFHexString } from "pdf-lib";
+
+/**
+ * Page label numbering styles, keyed by their /S value in the PDF spec.
+ * "" means prefix only, with no number.
+ */
+export const PAGE_LABEL_STYLES = {
+  D: "1, 2, 3",
+  r: "i, ii, iii",
+  R: "I, II, III",
+  a: "a, b, c",
+  A: "A, B, C",
+  "": "Prefix only",
+};
+
+/**
+ * Write a /PageLabels number tree to the document catalog so viewers show
+ * logical page numbers (e.g. "iv" or "A-1") instead of physical ones.
+ *
+ * Each range is { startPage, style, prefix, start } and runs until the next
+ * range begins. Ranges are sorted and deduplicated by startPage; page 1 is
+ * given decimal numbering when no range covers it, as the spec requires.
+ */
+export function applyPageLabels(pdfDoc, ranges) {
+  const total = pdfDoc.getPageCount();
+  const byStart = new Map();
+  for (const range of ranges) {
+    const startPage = Math.round(range.startPage);
+    if (startPage >= 1 && startPage <= total) byStart.set(startPage, range);
+  }
+  if (byStart.size === 0) return;
+  if (!byStart.has(1)) byStart.set(1, { startPage: 1, style: "D" });
+
+  const nums = [];
+  for (const [startPage, range] of [...byStart].sort((a, b) => a[0] - b[0])) {
+    const label = {};
+    if (range.style) label.S = PDFName.of(range.style);
+    if (range.prefix) label.P = PDFHexString.fromText(range.prefix);
+    if (range.start > 1) label.St = Math.round(range.start);
+    nums.push(startPage - 1, pdfDoc.context.obj(label));
+  }
+  pdfDoc.catalog.set(PDFName.of("PageLabels"), pdfDoc.context.obj({ Nums: nums }));
+}
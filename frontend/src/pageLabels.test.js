@@ -0,0 +1,90 @@
+import { test } from "node:test";
+import assert from "node:assert/strict";
+import { PDFDocument, PDFName } from "pdf-lib";
+import { applyPageLabels } from "./pageLabels.js";
+
+async function documentWithPages(count) {
+  const pdfDoc = await PDFDocument.create();
+  for (let i = 0; i < count; i++) pdfDoc.addPage();
+  return pdfDoc;
+}
+
+// The catalog's /PageLabels as plain { index, style, prefix, start } entries
+function readLabels(pdfDoc) {
+  const tree = pdfDoc.catalog.get(PDFName.of("PageLabels"));
+  if (!tree) return null;
+  const nums = tree.get(PDFName.of("Nums")).asArray();
+  const labels = [];
+  for (let i = 0; i < nums.length; i += 2) {
+    const label = { index: nums[i].asNumber() };
+    const style = nums[i + 1].get(PDFName.of("S"));
+    const prefix = nums[i + 1].get(PDFName.of("P"));
+    const start = nums[i + 1].get(PDFName.of("St"));
+    if (style) label.style = style.decodeText();
+    if (prefix) label.prefix = prefix.decodeText();
+    if (start) label.start = start.asNumber();
+    labels.push(label);
+  }
+  return labels;
+}
+
+test("writes ranges in page order with zero-based indexes", async () => {
+  const pdfDoc = await documentWithPages(10);
+  applyPageLabels(pdfDoc, [
+    { startPage: 5, style: "D", prefix: "A-", start: 1 },
+    { startPage: 1, style: "r" },
+  ]);
+  assert.deepEqual(readLabels(pdfDoc), [
+    { index: 0, style: "r" },
+    { index: 4, style: "D", prefix: "A-" },
+  ]);
+});
+
+test("numbers page 1 in decimal when no range covers it", async () => {
+  const pdfDoc = await documentWithPages(4);
+  applyPageLabels(pdfDoc, [{ startPage: 3, style: "A", start: 5 }]);
+  assert.deepEqual(readLabels(pdfDoc), [
+    { index: 0, style: "D" },
+    { index: 2, style: "A", start: 5 },
+  ]);
+});
+
+test("keeps the last of several ranges starting on the same page", async () => {
+  const pdfDoc = await documentWithPages(3);
+  applyPageLabels(pdfDoc, [
+    { startPage: 1, style: "R" },
+    { startPage: 1.2, style: "a" },
+  ]);
+  assert.deepEqual(readLabels(pdfDoc), [{ index: 0, style: "a" }]);
+});
+
+test("writes a prefix-only range without a style", async () => {
+  const pdfDoc = await documentWithPages(2);
+  applyPageLabels(pdfDoc, [{ startPage: 1, style: "", prefix: "Cover" }]);
+  assert.deepEqual(readLabels(pdfDoc), [{ index: 0, prefix: "Cover" }]);
+});
+
+test("ignores ranges outside the document and writes nothing without any", async () => {
+  const pdfDoc = await documentWithPages(3);
+  applyPageLabels(pdfDoc, [
+    { startPage: 0, style: "r" },
+    { startPage: 4, style: "R" },
+    { startPage: NaN, style: "a" },
+  ]);
+  assert.equal(readLabels(pdfDoc), null);
+
+  applyPageLabels(pdfDoc, []);
+  assert.equal(readLabels(pdfDoc), null);
+});
+
+test("ignores start numbers of 1 or less", async () => {
+  const pdfDoc = await documentWithPages(2);
+  applyPageLabels(pdfDoc, [
+    { startPage: 1, style: "D", start: 0 },
+    { startPage: 2, style: "D", start: 1 },
+  ]);
+  assert.deepEqual(readLabels(pdfDoc), [
+    { index: 0, style: "D" },
+    { index: 1, style: "D" },
+  ]);
+});
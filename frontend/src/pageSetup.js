@@ -0,0 +1,340 @@
+import { degrees, PDFName, PDFNumber, PDFArray, PDFDict, PDFStream, PDFRef } from "pdf-lib";
+
+// Clockwise /Rotate of a page, snapped to 0, 90, 180 or 270
+function viewRotation(page) {
+  const quarterTurns = Math.round(page.getRotation().angle / 90);
+  return (((quarterTurns % 4) + 4) % 4) * 90;
+}
+
+// Entries holding a flat list of x, y points in page coordinates
+const ANNOT_POINT_KEYS = ["QuadPoints", "Vertices", "L", "CL"];
+
+// Page boxes other than the MediaBox and CropBox, kept when set
+const EXTRA_BOXES = ["TrimBox", "BleedBox", "ArtBox"];
+
+// Annotation flag for appearances that don't turn with the page
+const NO_ROTATE = 16;
+
+function numbers(context, array) {
+  return array
+    .asArray()
+    .map((n) => context.lookup(n))
+    .filter((n) => n instanceof PDFNumber)
+    .map((n) => n.asNumber());
+}
+
+// Map a flat [x1, y1, x2, y2, ...] list through `map`
+function mapPoints(nums, map) {
+  const out = [];
+  for (let i = 0; i + 1 < nums.length; i += 2) out.push(...map(nums[i], nums[i + 1]));
+  return out;
+}
+
+// The bounding rectangle of [llx, lly, urx, ury] once its corners are mapped
+function mapRect(context, rect, map) {
+  const [x1, y1, x2, y2] = numbers(context, rect);
+  const corners = mapPoints([x1, y1, x2, y1, x2, y2, x1, y2], map);
+  const xs = corners.filter((_, i) => i % 2 === 0);
+  const ys = corners.filter((_, i) => i % 2 === 1);
+  return context.obj([Math.min(...xs), Math.min(...ys), Math.max(...xs), Math.max(...ys)]);
+}
+
+// Every stream an annotation's /AP dictionary can draw
+function appearanceStreams(context, annot) {
+  const ap = annot.lookup(PDFName.of("AP"));
+  if (!(ap instanceof PDFDict)) return [];
+  return ["N", "R", "D"].flatMap((key) => {
+    const entry = ap.lookup(PDFName.of(key));
+    if (entry instanceof PDFStream) return [entry];
+    if (entry instanceof PDFDict) return entry.values().map((v) => context.lookup(v));
+    return [];
+  }).filter((stream) => stream instanceof PDFStream);
+}
+
+/**
+ * Keep an annotation on the content it belongs to after the page is
+ * rebuilt: its geometry goes through `map`, and its appearances (unless
+ * marked NoRotate) turn `ccw` degrees counter-clockwise with the content.
+ * Streams already in `turned` were shared with an earlier annotation.
+ */
+function moveAnnotation(context, annot, map, ccw, turned) {
+  const rect = annot.lookup(PDFName.of("Rect"));
+  if (rect instanceof PDFArray) annot.set(PDFName.of("Rect"), mapRect(context, rect, map));
+  for (const key of ANNOT_POINT_KEYS) {
+    const points = annot.lookup(PDFName.of(key));
+    if (points instanceof PDFArray) {
+      annot.set(PDFName.of(key), context.obj(mapPoints(numbers(context, points), map)));
+    }
+  }
+  const ink = annot.lookup(PDFName.of("InkList"));
+  if (ink instanceof PDFArray) {
+    const paths = ink.asArray().map((path) => mapPoints(numbers(context, context.lookup(path)), map));
+    annot.set(PDFName.of("InkList"), context.obj(paths));
+  }
+
+  const flags = annot.lookup(PDFName.of("F"));
+  if (!ccw || (flags instanceof PDFNumber && flags.asNumber() & NO_ROTATE)) return;
+
+  // An appearance's /Matrix maps its BBox before it's fitted to /Rect, so
+  // turning the matrix turns the drawing inside the (already turned) Rect
+  const [cos, sin] = { 90: [0, 1], 180: [-1, 0], 270: [0, -1] }[ccw];
+  for (const stream of appearanceStreams(context, annot)) {
+    if (turned.has(stream)) continue;
+    turned.add(stream);
+    const matrix = stream.dict.lookup(PDFName.of("Matrix"));
+    const [a, b, c, d, e, f] =
+      matrix instanceof PDFArray ? numbers(context, matrix) : [1, 0, 0, 1, 0, 0];
+    stream.dict.set(
+      PDFName.of("Matrix"),
+      context.obj([
+        a * cos - b * sin,
+        a * sin + b * cos,
+        c * cos - d * sin,
+        c * sin + d * cos,
+        e * cos - f * sin,
+        e * sin + f * cos,
+      ])
+    );
+  }
+
+  // Widgets also record their rotation for viewers that redraw them
+  if (annot.get(PDFName.of("Subtype")) === PDFName.of("Widget")) {
+    let mk = annot.lookup(PDFName.of("MK"));
+    if (!(mk instanceof PDFDict)) {
+      mk = context.obj({});
+      annot.set(PDFName.of("MK"), mk);
+    }
+    const rotation = mk.lookup(PDFName.of("R"));
+    const current = rotation instanceof PDFNumber ? rotation.asNumber() : 0;
+    mk.set(PDFName.of("R"), PDFNumber.of((current + ccw) % 360));
+  }
+}
+
+// The refs making up a page's /Contents, the array's own ref included
+function contentRefs(page) {
+  const contents = page.node.get(PDFName.of("Contents"));
+  const refs = contents instanceof PDFRef ? [contents] : [];
+  const array = page.node.context.lookup(contents);
+  if (array instanceof PDFArray) refs.push(...array.asArray().filter((r) => r instanceof PDFRef));
+  return refs;
+}
+
+/**
+ * Make every page portrait (or landscape) as displayed. Mismatched pages are
+ * either turned a quarter turn ("rotate") or shrunk and centered upright on
+ * a page of the other orientation ("scale").
+ *
+ * Pages that change, and pages with a /Rotate entry, are rebuilt unrotated
+ * with their old content drawn onto them, so the output's page boxes match
+ * what the viewer shows and overlay percentages line up. The page object
+ * itself is kept, so bookmarks, links and form fields still point at it;
+ * its annotations are moved with the content and its old content streams
+ * are removed. Returns the number of pages whose orientation changed.
+ */
+export async function normalizeOrientation(pdfDoc, { orientation = "portrait", mode = "rotate" }) {
+  const { context } = pdfDoc;
+  const oldContents = new Set();
+  const turned = new Set();
+  let changed = 0;
+  for (const page of pdfDoc.getPages()) {
+    const angle = viewRotation(page);
+    const box = page.getCropBox();
+    const { width, height } = box;
+    const sideways = angle === 90 || angle === 270;
+    const shownLandscape = sideways ? height > width : width > height;
+    const shownPortrait = sideways ? width > height : height > width;
+    const wrong = orientation === "portrait" ? shownLandscape : shownPortrait;
+    if (!wrong && angle === 0) continue;
+
+    // Total clockwise rotation of the content as it will appear
+    const turn = (angle + (wrong && mode === "rotate" ? 90 : 0)) % 360;
+    const turned90 = turn === 90 || turn === 270;
+    const [shownWidth, shownHeight] = turned90 ? [height, width] : [width, height];
+
+    // Scale mode keeps content upright on a page of swapped dimensions
+    const [pageWidth, pageHeight] =
+      wrong && mode === "scale" ? [shownHeight, shownWidth] : [shownWidth, shownHeight];
+    const scale = Math.min(pageWidth / shownWidth, pageHeight / shownHeight);
+    const offsetX = (pageWidth - shownWidth * scale) / 2;
+    const offsetY = (pageHeight - shownHeight * scale) / 2;
+
+    // drawPage rotates counter-clockwise about its anchor, so the anchor
+    // moves to whichever corner ends up bottom-left
+    const ccw = (360 - turn) % 360;
+    const w = width * scale;
+    const h = height * scale;
+    const anchor = { 0: [0, 0], 90: [h, 0], 180: [w, h], 270: [0, w] }[ccw];
+    const originX = offsetX + anchor[0];
+    const originY = offsetY + anchor[1];
+
+    // Where a point on the old page lands on the rebuilt one
+    const [cos, sin] = { 0: [1, 0], 90: [0, 1], 180: [-1, 0], 270: [0, -1] }[ccw];
+    const map = (px, py) => {
+      const u = (px - box.x) * scale;
+      const v = (py - box.y) * scale;
+      return [originX + u * cos - v * sin, originY + u * sin + v * cos];
+    };
+
+    // Only the visible area is carried over, so earlier trims still apply.
+    // Embedding now captures the content before the page is rewritten.
+    const embedded = await pdfDoc.embedPage(page, {
+      left: box.x,
+      bottom: box.y,
+      right: box.x + width,
+      top: box.y + height,
+    });
+    await embedded.embed();
+    contentRefs(page).forEach((ref) => oldContents.add(ref));
+
+    for (const key of EXTRA_BOXES) {
+      const extra = page.node.lookup(PDFName.of(key));
+      if (extra instanceof PDFArray) page.node.set(PDFName.of(key), mapRect(context, extra, map));
+    }
+    for (const ref of page.node.Annots()?.asArray() || []) {
+      const annot = context.lookup(ref);
+      if (annot instanceof PDFDict) moveAnnotation(context, annot, map, ccw, turned);
+    }
+
+    page.node.delete(PDFName.of("Contents"));
+    page.node.set(
+      PDFName.of("Resources"),
+      context.obj({ Font: {}, XObject: {}, ExtGState: {} })
+    );
+    page.setRotation(degrees(0));
+    page.setMediaBox(0, 0, pageWidth, pageHeight);
+    page.setCropBox(0, 0, pageWidth, pageHeight);
+    page.drawPage(embedded, {
+      x: originX,
+      y: originY,
+      xScale: scale,
+      yScale: scale,
+      rotate: degrees(ccw),
+    });
+    if (wrong) changed++;
+  }
+
+  // The old streams now live in the embedded copies; keep any another
+  // page still draws
+  for (const page of pdfDoc.getPages()) contentRefs(page).forEach((ref) => oldContents.delete(ref));
+  oldContents.forEach((ref) => context.delete(ref));
+  return changed;
+}
+
+/**
+ * Grow (positive) or trim (negative) each page by the given amounts in
+ * points, per edge as the page is displayed, starting from the visible
+ * (crop) area. Only the page boxes change, so content keeps its place on
+ * the paper and links and form fields are unaffected. Edges are mapped
+ * through /Rotate; trims that would leave less than an inch are refused.
+ * Returns the number of pages changed.
+ */
+export function adjustMargins(pdfDoc, { top = 0, right = 0, bottom = 0, left = 0 }) {
+  if (!top && !right && !bottom && !left) return 0;
+
+  const pages = pdfDoc.getPages();
+  for (const page of pages) {
+    // Displayed edges in raw top, right, bottom, left order for each rotation
+    const raw = {
+      0: [top, right, bottom, left],
+      90: [right, bottom, left, top],
+      180: [bottom, left, top, right],
+      270: [left, top, right, bottom],
+    }[viewRotation(page)];
+    const [t, r, b, l] = raw;
+
+    // Start from the visible area, so an existing crop stays in effect, and
+    // only grow the MediaBox where the new box reaches past it
+    const box = page.getCropBox();
+    const width = box.width + l + r;
+    const height = box.height + t + b;
+    if (width < 72 || height < 72) {
+      throw new Error("Trimming that much would leave less than an inch of page");
+    }
+    const x = box.x - l;
+    const y = box.y - b;
+    const media = page.getMediaBox();
+    const mediaX = Math.min(media.x, x);
+    const mediaY = Math.min(media.y, y);
+    page.setMediaBox(
+      mediaX,
+      mediaY,
+      Math.max(media.x + media.width, x + width) - mediaX,
+      Math.max(media.y + media.height, y + height) - mediaY
+    );
+    page.setCropBox(x, y, width, height);
+  }
+  return pages.length;
+}
+
+/**
+ * Crop each page to its detected content box (see pageInk.js) plus
+ * `padding` points on every side, never growing past the current page.
+ * Blank pages (null boxes) are left as they are. Returns the number of
+ * pages changed.
+ */
+export function cropToContent(pdfDoc, boxes, padding = 0) {
+  let changed = 0;
+  pdfDoc.getPages().forEach((page, i) => {
+    if (!boxes[i]) return;
+    const box = page.getCropBox();
+    const x1 = Math.max(box.x, boxes[i][0] - padding);
+    const y1 = Math.max(box.y, boxes[i][1] - padding);
+    const x2 = Math.min(box.x + box.width, boxes[i][2] + padding);
+    const y2 = Math.min(box.y + box.height, boxes[i][3] + padding);
+    if (x2 - x1 < 1 || y2 - y1 < 1) return;
+
+    page.setMediaBox(x1, y1, x2 - x1, y2 - y1);
+    page.setCropBox(x1, y1, x2 - x1, y2 - y1);
+    changed++;
+  });
+  return changed;
+}
+
+// Form fields draw nothing when pdf.js renders a page, so a page holding
+// only empty fields would look blank
+function hasWidgets(page) {
+  return (page.node.Annots()?.asArray() || []).some((ref) => {
+    const annot = page.node.context.lookup(ref);
+    return annot instanceof PDFDict && annot.get(PDFName.of("Subtype")) === PDFName.of("Widget");
+  });
+}
+
+/**
+ * Remove pages with no ink, or whose ink coverage (from detectPageInk in
+ * pageInk.js) is below `thresholdPercent` of the page. Pages with form
+ * fields are kept. Removed pages are deleted along with their content and
+ * annotations, and links or bookmarks to them resolve to nothing. Returns
+ * the removed 1-based page numbers.
+ */
+export function removeBlankPages(pdfDoc, ink, thresholdPercent) {
+  const { context } = pdfDoc;
+  const pages = pdfDoc.getPages();
+  const blank = pages.map(
+    (page, i) =>
+      !!ink[i] && (ink[i].coverage === 0 || ink[i].coverage * 100 < thresholdPercent) && !hasWidgets(page)
+  );
+  if (blank.every(Boolean)) {
+    throw new Error("Every page looks blank at this threshold; lower it to keep some");
+  }
+
+  const doomed = new Set();
+  const removed = [];
+  for (let i = pages.length - 1; i >= 0; i--) {
+    if (!blank[i]) continue;
+    const page = pages[i];
+    contentRefs(page).forEach((ref) => doomed.add(ref));
+    const annots = page.node.get(PDFName.of("Annots"));
+    if (annots instanceof PDFRef) doomed.add(annots);
+    (page.node.Annots()?.asArray() || [])
+      .filter((ref) => ref instanceof PDFRef)
+      .forEach((ref) => doomed.add(ref));
+    doomed.add(page.ref);
+    pdfDoc.removePage(i);
+    removed.unshift(i + 1);
+  }
+
+  // Keep any content stream a remaining page shares
+  for (const page of pdfDoc.getPages()) contentRefs(page).forEach((ref) => doomed.delete(ref));
+  doomed.forEach((ref) => context.delete(ref));
+  return removed;
+}
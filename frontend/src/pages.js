@@ -0,0 +1,120 @@
+/**
+ * Parse a page selection such as "1-3,7", "5-", "all", "even" or "odd" into
+ * a sorted list of unique 1-based page numbers within 1..totalPages.
+ * Tokens that don't parse or fall outside the document are ignored.
+ */
+export function parsePageSelection(spec, totalPages) {
+  const pages = new Set();
+  const add = (from, to) => {
+    for (let p = Math.max(1, from); p <= Math.min(totalPages, to); p++) pages.add(p);
+  };
+
+  for (const raw of String(spec).split(",")) {
+    const token = raw.trim().toLowerCase();
+    if (token === "all") {
+      add(1, totalPages);
+    } else if (token === "even" || token === "odd") {
+      for (let p = token === "even" ? 2 : 1; p <= totalPages; p += 2) pages.add(p);
+    } else if (/^\d+$/.test(token)) {
+      add(parseInt(token), parseInt(token));
+    } else if (/^\d+\s*-\s*\d*$/.test(token)) {
+      const [from, to] = token.split("-").map((n) => n.trim());
+      add(parseInt(from), to ? parseInt(to) : totalPages);
+    }
+  }
+  return [...pages].sort((a, b) => a - b);
+}
+
+/**
+ * Pages an overlay is applied to: its `pages` selection when set,
+ * otherwise the single page it was placed on.
+ */
+export function overlayPages(overlay, totalPages) {
+  if (overlay.pages && overlay.pages.trim()) {
+    return parsePageSelection(overlay.pages, totalPages);
+  }
+  const page = overlay.page || 1;
+  return page >= 1 && page <= totalPages ? [page] : [];
+}
+
+/**
+ * Whether an overlay appears on the given 1-based page.
+ */
+export function isOnPage(overlay, page, totalPages) {
+  return overlayPages(overlay, totalPages).includes(page);
+}
+
+/**
+ * Where each page ends up once `count` pages are inserted in front of
+ * 1-based page `before` of a `totalPages` document, indexed by old page
+ * number (index 0 is unused).
+ */
+export function pageMapForInsert(before, count, totalPages) {
+  const map = [null];
+  for (let p = 1; p <= totalPages; p++) map.push(p < before ? p : p + count);
+  return map;
+}
+
+/**
+ * Where each page ends up once the 1-based `removed` pages are deleted from
+ * a `totalPages` document, indexed by old page number; removed pages (and
+ * index 0) map to null.
+ */
+export function pageMapForRemoval(removed, totalPages) {
+  const gone = new Set(removed);
+  const map = [null];
+  let next = 1;
+  for (let p = 1; p <= totalPages; p++) map.push(gone.has(p) ? null : next++);
+  return map;
+}
+
+/**
+ * Write a sorted list of page numbers as a selection, e.g. "1-3,7".
+ */
+export function formatPageSelection(pages) {
+  const runs = [];
+  for (const p of pages) {
+    const run = runs[runs.length - 1];
+    if (run && p === run[1] + 1) run[1] = p;
+    else runs.push([p, p]);
+  }
+  return runs.map(([from, to]) => (from === to ? `${from}` : `${from}-${to}`)).join(",");
+}
+
+const POSITIONAL_TOKENS = new Set(["all", "even", "odd"]);
+
+/**
+ * Keep overlays on the content they were placed on when pages are added or
+ * taken away, given a map from the old `totalPages` page numbers to new
+ * ones (null for pages that are gone). Overlays left with no page are
+ * dropped. Selections made only of "all", "even" and "odd" describe
+ * positions and stay as they are; others are rewritten with the new numbers.
+ */
+export function renumberOverlays(overlays, map, totalPages) {
+  return overlays.flatMap((o) => {
+    if (o.pages && o.pages.trim()) {
+      const tokens = o.pages.split(",").map((t) => t.trim().toLowerCase());
+      if (tokens.every((t) => POSITIONAL_TOKENS.has(t))) {
+        return [{ ...o, page: map[o.page] || 1 }];
+      }
+      const kept = parsePageSelection(o.pages, totalPages)
+        .map((p) => map[p])
+        .filter(Boolean);
+      if (kept.length === 0) return [];
+      return [{ ...o, pages: formatPageSelection(kept), page: map[o.page] || kept[0] }];
+    }
+    return map[o.page] ? [{ ...o, page: map[o.page] }] : [];
+  });
+}
+
+/**
+ * Move page label ranges with their pages, given a map as for
+ * renumberOverlays. A range whose first page is gone starts at the next
+ * page that remains; ranges left with no page are dropped.
+ */
+export function renumberPageLabels(ranges, map) {
+  return ranges.flatMap((range) => {
+    const startPage = map.slice(range.startPage).find(Boolean);
+    return startPage ? [{ ...range, startPage }] : [];
+  });
+}
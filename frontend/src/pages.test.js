@@ -0,0 +1,91 @@
+import { test } from "node:test";
+import assert from "node:assert/strict";
+import {
+  formatPageSelection,
+  overlayPages,
+  pageMapForInsert,
+  pageMapForRemoval,
+  parsePageSelection,
+  renumberOverlays,
+} from "./pages.js";
+
+test("parses single pages, ranges and open-ended ranges", () => {
+  assert.deepEqual(parsePageSelection("1-3,7", 10), [1, 2, 3, 7]);
+  assert.deepEqual(parsePageSelection(" 2 - 4 , 9", 10), [2, 3, 4, 9]);
+  assert.deepEqual(parsePageSelection("8-", 10), [8, 9, 10]);
+  assert.deepEqual(parsePageSelection("3,1,3,2", 10), [1, 2, 3]);
+});
+
+test("parses all, even and odd in any case", () => {
+  assert.deepEqual(parsePageSelection("ALL", 3), [1, 2, 3]);
+  assert.deepEqual(parsePageSelection("even", 5), [2, 4]);
+  assert.deepEqual(parsePageSelection("Odd", 5), [1, 3, 5]);
+  assert.deepEqual(parsePageSelection("even", 1), []);
+});
+
+test("ignores reversed ranges, out-of-range pages and junk", () => {
+  assert.deepEqual(parsePageSelection("5-3", 10), []);
+  assert.deepEqual(parsePageSelection("0,11,12-20", 10), []);
+  assert.deepEqual(parsePageSelection("8-12", 10), [8, 9, 10]);
+  assert.deepEqual(parsePageSelection("abc,-2,1.5,,2", 10), [2]);
+  assert.deepEqual(parsePageSelection("", 10), []);
+});
+
+test("an overlay without a selection stays on its own page", () => {
+  assert.deepEqual(overlayPages({ page: 3 }, 5), [3]);
+  assert.deepEqual(overlayPages({ page: 3, pages: "  " }, 5), [3]);
+  assert.deepEqual(overlayPages({ page: 9 }, 5), []);
+  assert.deepEqual(overlayPages({ page: 1, pages: "4-" }, 5), [4, 5]);
+});
+
+test("formats page lists as compact selections", () => {
+  assert.equal(formatPageSelection([1, 2, 3, 7]), "1-3,7");
+  assert.equal(formatPageSelection([2, 4, 5]), "2,4-5");
+  assert.equal(formatPageSelection([]), "");
+});
+
+test("maps old page numbers across inserted and removed pages", () => {
+  assert.deepEqual(pageMapForInsert(2, 2, 3), [null, 1, 4, 5]);
+  assert.deepEqual(pageMapForInsert(4, 1, 3), [null, 1, 2, 3]);
+  assert.deepEqual(pageMapForRemoval([2, 4], 5), [null, 1, null, 2, null, 3]);
+});
+
+test("drops overlays whose pages are removed", () => {
+  const map = pageMapForRemoval([2], 3);
+  const overlays = [
+    { id: "a", page: 1 },
+    { id: "b", page: 2 },
+    { id: "c", page: 3 },
+    { id: "d", page: 1, pages: "2" },
+  ];
+  assert.deepEqual(renumberOverlays(overlays, map, 3), [
+    { id: "a", page: 1 },
+    { id: "c", page: 2 },
+  ]);
+});
+
+test("rewrites numbered selections and keeps all/even/odd positional", () => {
+  const map = pageMapForRemoval([1], 4);
+  const overlays = [
+    { id: "a", page: 2, pages: "1-3" },
+    { id: "b", page: 1, pages: "even" },
+    { id: "c", page: 3, pages: "all, odd" },
+  ];
+  assert.deepEqual(renumberOverlays(overlays, map, 4), [
+    { id: "a", page: 1, pages: "1-2" },
+    { id: "b", page: 1, pages: "even" },
+    { id: "c", page: 2, pages: "all, odd" },
+  ]);
+});
+
+test("moves overlays along when pages are inserted", () => {
+  const map = pageMapForInsert(1, 1, 3);
+  const overlays = [
+    { id: "a", page: 1 },
+    { id: "b", page: 2, pages: "2,3" },
+  ];
+  assert.deepEqual(renumberOverlays(overlays, map, 3), [
+    { id: "a", page: 2 },
+    { id: "b", page: 3, pages: "3-4" },
+  ]);
+});
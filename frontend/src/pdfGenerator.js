@@ -1,20 +1,157 @@
-import { PDFDocument, rgb, StandardFonts } from "pdf-lib";
+import {
+  PDFDocument,
+  PDFPage,
+  PDFArray,
+  PDFName,
+  rgb,
+  degrees,
+  BlendMode,
+  LineCapStyle,
+  LineJoinStyle,
+  TextRenderingMode,
+  pushGraphicsState,
+  popGraphicsState,
+  setGraphicsState,
+  setTextRenderingMode,
+  setStrokingColor,
+  setLineWidth,
+  setLineJoin,
+  drawObject,
+} from "pdf-lib";
+import { STAMP_GLYPHS, STAMP_STROKE, scaleStampPath } from "./stamps";
+import { standardFontName } from "./fonts";
+import { overlayPages } from "./pages";
+import { formatBates } from "./bates";
+import { applyPageLabels } from "./pageLabels";
+import { sanitizeDocument, scrubMetadata } from "./sanitize";
+import { fillForm } from "./formData";
+import { PdfEditError, isEncodingError } from "./errors";
+import { parseColor, COLOR_FIELDS } from "./colors";
 
 /**
- * Parse a hex color string (#RRGGBB) into pdf-lib rgb() values.
+ * Resolve an overlay color (any form colors.js accepts) to pdf-lib rgb()
+ * values plus its alpha, which multiplies the overlay's opacity. Colors
+ * are checked before drawing, so an unparseable one here means unset.
  */
-function hexToRgb(hex) {
-  const h = hex.replace("#", "");
-  const r = parseInt(h.substring(0, 2), 16) / 255;
-  const g = parseInt(h.substring(2, 4), 16) / 255;
-  const b = parseInt(h.substring(4, 6), 16) / 255;
-  return rgb(r, g, b);
+function resolveColor(value, fallback) {
+  const c = value && parseColor(value);
+  return c ? { color: rgb(c.r, c.g, c.b), alpha: c.alpha } : { color: fallback, alpha: 1 };
+}
+
+/**
+ * Expand {name} placeholders in overlay text. Names may contain anything
+ * but braces, so data headers like {First Name} or {e-mail} work as they
+ * appear. Unknown names are left as-is so literal braces in user text
+ * survive untouched.
+ */
+function expandVariables(text, vars) {
+  return text.replace(/\{([^{}]+)\}/g, (match, name) =>
+    Object.hasOwn(vars, name) ? String(vars[name]) : match
+  );
+}
+
+/**
+ * The variables available to every text overlay in a document of
+ * `pageCount` pages; {page} and {bates} are added per page by overlayText().
+ */
+export function baseVariables(pageCount, { fileName, variables } = {}) {
+  const now = new Date();
+  return {
+    date: now.toLocaleDateString(),
+    time: now.toLocaleTimeString(),
+    totalPages: pageCount,
+    filename: fileName || "",
+    ...variables,
+  };
+}
+
+/**
+ * A text overlay's text as drawn on `pageNumber`, the `ordinal`-th page of
+ * its selection (which numbers Bates stamps after `batesOffset`).
+ */
+export function overlayText(overlay, vars, pageNumber, ordinal, batesOffset = 0) {
+  return expandVariables(overlay.text || "", {
+    ...vars,
+    page: pageNumber,
+    ...(overlay.bates && { bates: formatBates(overlay.bates, batesOffset + ordinal) }),
+  });
+}
+
+/**
+ * Break text into lines that fit maxWidth (in points) at the given size.
+ * Line breaks (\n, \r\n or a lone \r) are always honored; without maxWidth
+ * no wrapping occurs. Words longer than a whole line are broken between
+ * characters.
+ */
+export function wrapText(text, font, fontSize, maxWidth) {
+  const paragraphs = text.split(/\r\n|\r|\n/);
+  if (!maxWidth) return paragraphs;
+
+  const fits = (s) => font.widthOfTextAtSize(s, fontSize) <= maxWidth;
+  const lines = [];
+  for (const paragraph of paragraphs) {
+    let line = "";
+    for (const word of paragraph.split(/ +/)) {
+      const candidate = line ? `${line} ${word}` : word;
+      if (fits(candidate)) {
+        line = candidate;
+        continue;
+      }
+      if (line) lines.push(line);
+      line = word;
+      while (line.length > 1 && !fits(line)) {
+        let cut = line.length - 1;
+        while (cut > 1 && !fits(line.substring(0, cut))) cut--;
+        lines.push(line.substring(0, cut));
+        line = line.substring(cut);
+      }
+    }
+    lines.push(line);
+  }
+  return lines;
+}
+
+/**
+ * pdf-lib rotates drawn content around its bottom-left anchor, while overlays
+ * rotate around their top-left corner (as in the editor). Given the top-left
+ * corner in PDF coordinates and where the anchor sits relative to it (dx to
+ * the right, drop below), return the anchor position after rotating
+ * counterclockwise by `angle` degrees.
+ */
+function rotatedAnchor(topLeftX, topLeftY, dx, drop, angle) {
+  const rad = (angle * Math.PI) / 180;
+  return {
+    x: topLeftX + dx * Math.cos(rad) + drop * Math.sin(rad),
+    y: topLeftY + dx * Math.sin(rad) - drop * Math.cos(rad),
+  };
+}
+
+/**
+ * SVG path for a rectangle with rounded corners, y pointing down as
+ * drawSvgPath expects. The radius is limited to half the shorter side.
+ */
+function roundedRectPath(x, y, width, height, radius) {
+  const r = Math.min(Math.max(radius, 0), width / 2, height / 2);
+  if (!r) return `M ${x} ${y} H ${x + width} V ${y + height} H ${x} Z`;
+  const arc = (toX, toY) => `A ${r} ${r} 0 0 1 ${toX} ${toY}`;
+  return [
+    `M ${x + r} ${y}`,
+    `H ${x + width - r}`,
+    arc(x + width, y + r),
+    `V ${y + height - r}`,
+    arc(x + width - r, y + height),
+    `H ${x + r}`,
+    arc(x, y + height - r),
+    `V ${y + r}`,
+    arc(x + r, y),
+    "Z",
+  ].join(" ");
 }
 
 /**
  * Load a data URL into an HTMLImageElement.
  */
-function loadImage(dataUrl) {
+export function loadImage(dataUrl) {
   return new Promise((resolve, reject) => {
     const img = new Image();
     img.onload = () => resolve(img);
@@ -26,96 +163,558 @@ function loadImage(dataUrl) {
 /**
  * Normalize any image data URL to a clean PNG via canvas.
  * This handles WebP, GIF, interlaced PNGs, CMYK JPEGs, etc.
- * Returns a Uint8Array of PNG bytes that pdf-lib can always embed.
+ * Returns { bytes, format } that pdf-lib can always embed.
+ *
+ * Images larger than maxWidth x maxHeight pixels are scaled down (keeping
+ * their aspect ratio) until one side fits, and with jpegQuality set, images
+ * without any transparent pixels are encoded as JPEG instead.
  */
-async function normalizeImageToPng(dataUrl) {
+async function normalizeImage(dataUrl, { maxWidth = 0, maxHeight = 0, jpegQuality } = {}) {
   const img = await loadImage(dataUrl);
+  const scale =
+    maxWidth && maxHeight
+      ? Math.min(1, Math.max(maxWidth / img.naturalWidth, maxHeight / img.naturalHeight))
+      : 1;
   const canvas = document.createElement("canvas");
-  canvas.width = img.naturalWidth;
-  canvas.height = img.naturalHeight;
+  canvas.width = Math.max(1, Math.round(img.naturalWidth * scale));
+  canvas.height = Math.max(1, Math.round(img.naturalHeight * scale));
   const ctx = canvas.getContext("2d");
-  ctx.drawImage(img, 0, 0);
-  const blob = await new Promise((resolve) => canvas.toBlob(resolve, "image/png"));
-  return new Uint8Array(await blob.arrayBuffer());
+  ctx.imageSmoothingQuality = "high";
+  ctx.drawImage(img, 0, 0, canvas.width, canvas.height);
+
+  const format = jpegQuality && isOpaque(ctx, canvas) ? "jpeg" : "png";
+  const blob = await new Promise((resolve) =>
+    canvas.toBlob(resolve, `image/${format}`, jpegQuality)
+  );
+  return { bytes: new Uint8Array(await blob.arrayBuffer()), format };
+}
+
+function isOpaque(ctx, canvas) {
+  const { data } = ctx.getImageData(0, 0, canvas.width, canvas.height);
+  for (let i = 3; i < data.length; i += 4) {
+    if (data[i] < 255) return false;
+  }
+  return true;
+}
+
+/**
+ * Embed the source of an image overlay (normalized to PNG or JPEG, scaled
+ * to `size`) or a PDF stamp overlay (one page as a form XObject) into the
+ * document.
+ */
+async function embedSource(pdfDoc, overlay, size) {
+  if (overlay.type === "pdf") {
+    const [embeddedPage] = await pdfDoc.embedPdf(overlay.pdfData, [
+      (overlay.sourcePage || 1) - 1,
+    ]);
+    return embeddedPage;
+  }
+  // Normalize image via canvas (handles all formats)
+  const { bytes, format } = await normalizeImage(overlay.imageData, size);
+  return format === "jpeg" ? await pdfDoc.embedJpg(bytes) : await pdfDoc.embedPng(bytes);
+}
+
+/**
+ * A blank page outside the page tree with the same page boxes as `page`.
+ * Overlays are drawn onto it first when they must go under the page's
+ * existing content (see placeUnderContent) or be applied all at once.
+ */
+function createLayer(pdfDoc, page) {
+  const layer = PDFPage.create(pdfDoc);
+  const { x, y, width, height } = page.getMediaBox();
+  layer.setMediaBox(x, y, width, height);
+  const crop = page.getCropBox();
+  layer.setCropBox(crop.x, crop.y, crop.width, crop.height);
+  return layer;
+}
+
+// The layer was only a drawing surface. Drop it, and the streams pdf-lib
+// drew into, so they aren't written out as orphaned objects.
+function discardLayer(context, layer) {
+  layer.node.Contents()?.asArray().forEach((ref) => context.delete(ref));
+  context.delete(layer.ref);
+}
+
+/**
+ * Copy everything drawn on `layer` into a form XObject registered on
+ * `page` under a name starting with `prefix`, discard the layer, and
+ * return the name.
+ */
+async function embedLayer(pdfDoc, page, layer, prefix) {
+  // An identity matrix keeps the layer's coordinates, which are the page's
+  const form = await pdfDoc.embedPage(layer, undefined, [1, 0, 0, 1, 0, 0]);
+  await form.embed();
+  discardLayer(pdfDoc.context, layer);
+  return page.node.newXObject(prefix, form.ref);
+}
+
+// Paint everything drawn on `layer` on top of what `page` already has
+async function placeOverContent(pdfDoc, page, layer) {
+  const name = await embedLayer(pdfDoc, page, layer, "Layer");
+  page.pushOperators(pushGraphicsState(), drawObject(name), popGraphicsState());
+}
+
+/**
+ * Paint everything drawn on `layer` underneath `page`'s existing content.
+ * The layer becomes a form XObject, drawn by a new stream inserted first
+ * in the page's /Contents. That stream is wrapped in q/Q, so the original
+ * content still starts from the default graphics state it was written for.
+ */
+async function placeUnderContent(pdfDoc, page, layer) {
+  const { context } = pdfDoc;
+  const name = await embedLayer(pdfDoc, page, layer, "Under");
+  const streamRef = context.register(
+    context.contentStream([pushGraphicsState(), drawObject(name), popGraphicsState()])
+  );
+
+  page.node.normalize();
+  const contents = page.node.Contents();
+  if (contents instanceof PDFArray) {
+    contents.insert(0, streamRef);
+  } else {
+    page.node.set(PDFName.of("Contents"), context.obj([streamRef]));
+  }
 }
 
 /**
  * Generate a new PDF with text and image overlays applied client-side.
  *
+ * Overlays are drawn in array order regardless of type, so later entries
+ * paint over earlier ones exactly as they stack in the editor. Overlays
+ * marked `background` go underneath the page's existing content instead,
+ * still in array order among themselves. Overlays marked `multiply` are
+ * multiply-blended, so dark page content shows through them wherever they
+ * are drawn.
+ *
+ * Text overlays may contain {date}, {time}, {page}, {totalPages} and
+ * {filename} variables, which are expanded per page at generation time.
+ * Any extra `options.variables` (e.g. a mail-merge record) are expanded the
+ * same way. Text overlays with a `bates` config also get {bates}, numbered
+ * across the pages they're applied to and continuing from
+ * `options.batesOffset` when a production spans several documents.
+ *
  * @param {ArrayBuffer} pdfBytes - The original PDF file bytes
- * @param {Array} overlays - Array of overlay objects (type: "text" | "image")
+ * @param {Array} overlays - Array of overlay objects (type: "text" | "image" | "pdf" | "highlight" | "stamp")
+ * @param {string} [password] - Password for encrypted PDFs
+ * @param {Object} [options] - { fileName, variables, batesOffset } for text expansion,
+ *   pageLabels ranges for logical page numbering (see pageLabels.js),
+ *   imageQuality ({ dpi, jpegQuality }, see imageQuality.js) for image overlays,
+ *   sanitize to strip active content, reported through onSanitize(removed),
+ *   scrubMetadata to remove author, tool and XMP metadata,
+ *   formValues ({ fieldName: value }) to fill form fields before drawing,
+ *   continueOnError to skip failing overlays whole, reporting each PdfEditError
+ *   through onSkip(err) instead of throwing it,
+ *   signal (an AbortSignal) to cancel and timeoutMs to stop after a deadline,
+ *   onLoad({ pageCount }) called once the input document has been parsed,
+ *   and onProgress({ stage, done, total }) called as overlays are applied
  * @returns {Promise<Uint8Array>} - The modified PDF bytes
+ * @throws {PdfEditError} - Coded error; overlay failures carry details.index
  */
-export async function generatePDF(pdfBytes, overlays, password) {
+export async function generatePDF(pdfBytes, overlays, password, options = {}) {
+  // Cancellation and the deadline are checked between overlays and pages;
+  // a single draw call can't be interrupted
+  const deadline = options.timeoutMs ? Date.now() + options.timeoutMs : Infinity;
+  const checkCancelled = () => {
+    if (options.signal?.aborted) {
+      throw new PdfEditError("CANCELLED", "Generation was cancelled");
+    }
+    if (Date.now() > deadline) {
+      throw new PdfEditError(
+        "TIMED_OUT",
+        `Generation took longer than ${Math.round(options.timeoutMs / 1000)} seconds and was stopped`
+      );
+    }
+  };
+
   const loadOptions = password ? { password } : {};
-  const pdfDoc = await PDFDocument.load(pdfBytes, loadOptions);
+  if (options.scrubMetadata) loadOptions.updateMetadata = false;
+  let pdfDoc;
+  try {
+    pdfDoc = await PDFDocument.load(pdfBytes, loadOptions);
+  } catch (e) {
+    throw /encrypt|password/i.test(e.message)
+      ? new PdfEditError("PDF_ENCRYPTED", "The PDF is encrypted and couldn't be opened")
+      : new PdfEditError("PDF_INVALID", `The PDF couldn't be read: ${e.message}`);
+  }
   const pages = pdfDoc.getPages();
-  const helveticaFont = await pdfDoc.embedFont(StandardFonts.Helvetica);
+  options.onLoad?.({ pageCount: pages.length });
+
+  // Values that don't fit a field were already reported when imported
+  if (options.formValues) fillForm(pdfDoc, options.formValues);
 
-  for (const overlay of overlays) {
-    const pageIndex = (overlay.page || 1) - 1;
-    if (pageIndex < 0 || pageIndex >= pages.length) continue;
-    const page = pages[pageIndex];
-    const { width: pageWidth, height: pageHeight } = page.getSize();
+  // Embed each standard font at most once per document
+  const fonts = new Map();
+  const getFont = async (name) => {
+    if (!fonts.has(name)) fonts.set(name, await pdfDoc.embedFont(name));
+    return fonts.get(name);
+  };
+
+  const baseVars = baseVariables(pages.length, options);
+
+  // Pixels an image needs at the chosen DPI for the largest box it's
+  // placed in; without a DPI the original resolution is kept
+  const { dpi = 0, jpegQuality } = options.imageQuality || {};
+  const imageSize = (overlay) => {
+    if (!dpi) return { jpegQuality };
+    let maxWidth = 0;
+    let maxHeight = 0;
+    for (const pageNumber of overlayPages(overlay, pages.length)) {
+      const { width, height } = pages[pageNumber - 1].getCropBox();
+      maxWidth = Math.max(maxWidth, (overlay.width / 100) * width);
+      maxHeight = Math.max(maxHeight, (overlay.height / 100) * height);
+    }
+    return {
+      maxWidth: Math.ceil((maxWidth / 72) * dpi),
+      maxHeight: Math.ceil((maxHeight / 72) * dpi),
+      jpegQuality,
+    };
+  };
+
+  // Image and PDF stamp sources are embedded once per document (and target
+  // size), so one signature placed on many pages is only processed once
+  const embeds = new Map();
+  const getEmbedded = async (overlay) => {
+    const size = overlay.type === "image" ? imageSize(overlay) : null;
+    const key =
+      overlay.type === "pdf"
+        ? `${overlay.sourcePage || 1}:${overlay.pdfData}`
+        : dpi
+          ? `${size.maxWidth}x${size.maxHeight}:${overlay.imageData}`
+          : overlay.imageData;
+    if (!embeds.has(key)) embeds.set(key, await embedSource(pdfDoc, overlay, size));
+    return embeds.get(key);
+  };
+
+  // Progress callbacks yield to the event loop so the UI can repaint
+  const reportProgress = async (stage, done, total) => {
+    if (!options.onProgress) return;
+    options.onProgress({ stage, done, total });
+    await new Promise((resolve) => setTimeout(resolve, 0));
+  };
+
+  // Background overlays are collected on one layer per page and placed
+  // under its content once every overlay has been drawn
+  const layers = new Map();
+  const targetPage = (overlay, pageIndex) => {
+    if (!overlay.background) return pages[pageIndex];
+    if (!layers.has(pageIndex)) layers.set(pageIndex, createLayer(pdfDoc, pages[pageIndex]));
+    return layers.get(pageIndex);
+  };
+
+  // Draw one overlay on one page; `ordinal` is the page's position within
+  // the overlay's page selection, which numbers Bates stamps
+  const drawOverlay = async (overlay, page, pageNumber, ordinal, embedded) => {
+    // Positions are relative to the visible (crop) area, as the editor shows it
+    const { x: left, y: bottom, width: pageWidth, height: pageHeight } = page.getCropBox();
+    const top = bottom + pageHeight;
 
     if (overlay.type === "text") {
       const fontSize = overlay.fontSize || 14;
-      const color = overlay.color ? hexToRgb(overlay.color) : rgb(0, 0, 0);
+      const font = await getFont(standardFontName(overlay));
+      const { color, alpha } = resolveColor(overlay.color, rgb(0, 0, 0));
 
       // Frontend coordinates: x%, y% from top-left
-      const absX = (overlay.x / 100) * pageWidth;
+      const absX = left + (overlay.x / 100) * pageWidth;
       const absYFromTop = (overlay.y / 100) * pageHeight;
+      const rotation = overlay.rotation || 0;
+
+      const text = overlayText(overlay, baseVars, pageNumber, ordinal, options.batesOffset);
+
+      // Optional text box: width enables word wrap, height enables
+      // vertical alignment of the wrapped block inside the box
+      const boxWidth = overlay.width ? (overlay.width / 100) * pageWidth : 0;
+      const boxHeight = overlay.height ? (overlay.height / 100) * pageHeight : 0;
+      const lines = wrapText(text, font, fontSize, boxWidth);
+      const lineGap = fontSize * (overlay.lineHeight || 1.2);
+      const blockHeight = fontSize + (lines.length - 1) * lineGap;
+
+      let alignOffset = 0;
+      if (boxHeight && overlay.verticalAlign === "middle") {
+        alignOffset = (boxHeight - blockHeight) / 2;
+      } else if (boxHeight && overlay.verticalAlign === "bottom") {
+        alignOffset = boxHeight - blockHeight;
+      }
+
+      const opacity = overlay.opacity ?? 1;
+      const blendMode = overlay.multiply ? BlendMode.Multiply : undefined;
+
+      // Background box behind the text box, or behind the text itself when
+      // no box size is set; it shares the text's rotation around the
+      // top-left corner
+      if (overlay.backgroundColor) {
+        const fill = resolveColor(overlay.backgroundColor, rgb(1, 1, 1));
+        const padding = overlay.backgroundPadding ?? 4;
+        const descent = font.heightAtSize(fontSize) - font.heightAtSize(fontSize, { descender: false });
+        const width =
+          boxWidth || Math.max(...lines.map((line) => font.widthOfTextAtSize(line, fontSize)));
+        const height = boxHeight || blockHeight + descent;
+        page.drawSvgPath(
+          roundedRectPath(
+            -padding,
+            -padding,
+            width + 2 * padding,
+            height + 2 * padding,
+            overlay.backgroundRadius || 0
+          ),
+          {
+            x: absX,
+            y: top - absYFromTop,
+            rotate: degrees(rotation),
+            color: fill.color,
+            opacity: opacity * fill.alpha,
+            blendMode,
+          }
+        );
+      }
+
+      // An outline is stroked at twice its width underneath the fill, so
+      // the full width shows outside the glyphs. drawText has no stroke
+      // options, so the stroke state is set in a wrapping graphics state.
+      let outline = null;
+      if (overlay.strokeWidth > 0) {
+        const stroke = resolveColor(overlay.strokeColor, rgb(1, 1, 1));
+        const strokeState = page.node.newExtGState(
+          "GS",
+          pdfDoc.context.obj({ Type: "ExtGState", CA: opacity * stroke.alpha })
+        );
+        outline = [
+          setGraphicsState(strokeState),
+          setTextRenderingMode(TextRenderingMode.Outline),
+          setStrokingColor(stroke.color),
+          setLineWidth(overlay.strokeWidth * 2),
+          setLineJoin(LineJoinStyle.Round),
+        ];
+      }
 
       // PDF coordinate system: y=0 is bottom-left
-      // Place text so its top aligns with the overlay position
-      const pdfY = pageHeight - absYFromTop - fontSize;
+      // Place text so the top of the first line aligns with the box top,
+      // rotating every baseline around the box's top-left corner
+      lines.forEach((line, i) => {
+        const anchor = rotatedAnchor(
+          absX,
+          top - absYFromTop,
+          0,
+          alignOffset + fontSize + i * lineGap,
+          rotation
+        );
+        const textOptions = {
+          x: anchor.x,
+          y: anchor.y,
+          size: fontSize,
+          font,
+          color,
+          opacity: opacity * alpha,
+          rotate: degrees(rotation),
+          blendMode,
+        };
+        if (outline) {
+          // Restored even if drawing fails, so the page's later content
+          // isn't left stroked
+          page.pushOperators(pushGraphicsState(), ...outline);
+          try {
+            page.drawText(line, textOptions);
+          } finally {
+            page.pushOperators(popGraphicsState());
+          }
+        }
+        page.drawText(line, textOptions);
+      });
+    } else if (overlay.type === "highlight") {
+      const drawWidth = (overlay.width / 100) * pageWidth;
+      const drawHeight = (overlay.height / 100) * pageHeight;
+      const absX = left + (overlay.x / 100) * pageWidth;
+      const absYFromTop = (overlay.y / 100) * pageHeight;
+      const pdfY = top - absYFromTop - drawHeight;
 
-      page.drawText(overlay.text || "", {
+      // Multiply blending darkens only where the page is light, so the
+      // existing text stays readable as if the marker went under it.
+      const { color, alpha } = resolveColor(overlay.color, rgb(1, 0.92, 0.23));
+      page.drawRectangle({
         x: absX,
         y: pdfY,
-        size: fontSize,
-        font: helveticaFont,
+        width: drawWidth,
+        height: drawHeight,
         color,
+        opacity: (overlay.opacity ?? 0.4) * alpha,
+        blendMode: BlendMode.Multiply,
       });
-    } else if (overlay.type === "image" && overlay.imageData) {
-      // Normalize image to clean PNG via canvas (handles all formats)
-      let pngBytes;
-      try {
-        pngBytes = await normalizeImageToPng(overlay.imageData);
-      } catch (e) {
-        console.warn("Failed to normalize image, skipping:", e);
-        continue;
-      }
+    } else if (overlay.type === "stamp") {
+      const glyph = STAMP_GLYPHS[overlay.glyph] || STAMP_GLYPHS.check;
+      const size = overlay.size || 16;
+      const { color, alpha } = resolveColor(overlay.color, rgb(0, 0, 0));
+      const absX = left + (overlay.x / 100) * pageWidth;
+      const absYFromTop = (overlay.y / 100) * pageHeight;
 
-      let embeddedImage;
-      try {
-        embeddedImage = await pdfDoc.embedPng(pngBytes);
-      } catch (e) {
-        console.warn("Failed to embed image, skipping:", e);
-        continue;
+      // drawSvgPath's origin is the top-left of the path, y pointing down
+      const path = scaleStampPath(glyph.path, size);
+      const pathOptions = { x: absX, y: top - absYFromTop };
+      if (glyph.fill) {
+        page.drawSvgPath(path, { ...pathOptions, color, opacity: alpha });
+      } else {
+        page.drawSvgPath(path, {
+          ...pathOptions,
+          borderColor: color,
+          borderOpacity: alpha,
+          borderWidth: size * STAMP_STROKE,
+          borderLineCap: LineCapStyle.Round,
+        });
       }
-
+    } else if ((overlay.type === "image" || overlay.type === "pdf") && embedded) {
       // Calculate dimensions from percentage of page
-      const drawWidth = (overlay.width / 100) * pageWidth;
-      const drawHeight = (overlay.height / 100) * pageHeight;
+      const boxWidth = (overlay.width / 100) * pageWidth;
+      const boxHeight = (overlay.height / 100) * pageHeight;
+      let drawWidth = boxWidth;
+      let drawHeight = boxHeight;
+
+      // Either stretch to the box exactly, or fit inside it and center
+      if (overlay.preserveAspect) {
+        const scale = Math.min(
+          boxWidth / embedded.width,
+          boxHeight / embedded.height
+        );
+        drawWidth = embedded.width * scale;
+        drawHeight = embedded.height * scale;
+      }
+      const offsetX = (boxWidth - drawWidth) / 2;
+      const offsetY = (boxHeight - drawHeight) / 2;
 
       // Frontend: x%, y% from top-left corner of image
-      const absX = (overlay.x / 100) * pageWidth;
+      const absX = left + (overlay.x / 100) * pageWidth;
       const absYFromTop = (overlay.y / 100) * pageHeight;
 
-      // PDF y: bottom-left of the image
-      // top-of-image in PDF coords = pageHeight - absYFromTop
-      // bottom-of-image = top-of-image - drawHeight
-      const pdfY = pageHeight - absYFromTop - drawHeight;
+      // PDF anchor: bottom-left of the image
+      // top-of-box in PDF coords = top - absYFromTop
+      // bottom-of-image = top-of-box - offsetY - drawHeight (before rotation)
+      const rotation = overlay.rotation || 0;
+      const anchor = rotatedAnchor(
+        absX,
+        top - absYFromTop,
+        offsetX,
+        offsetY + drawHeight,
+        rotation
+      );
 
-      page.drawImage(embeddedImage, {
-        x: absX,
-        y: pdfY,
+      const drawOptions = {
+        x: anchor.x,
+        y: anchor.y,
         width: drawWidth,
         height: drawHeight,
+        opacity: overlay.opacity ?? 1,
+        rotate: degrees(rotation),
+        blendMode: overlay.multiply ? BlendMode.Multiply : undefined,
+      };
+      // PDF stamps stay vector: the source page is drawn as a form XObject
+      if (overlay.type === "pdf") {
+        page.drawPage(embedded, drawOptions);
+      } else {
+        page.drawImage(embedded, drawOptions);
+      }
+    }
+  };
+
+  // With continueOnError a failing overlay is reported and skipped (the
+  // rest of its pages included) instead of aborting the whole document
+  const fail = (err) => {
+    if (!options.continueOnError) throw err;
+    options.onSkip?.(err);
+  };
+
+  for (const [index, overlay] of overlays.entries()) {
+    await reportProgress("overlays", index, overlays.length);
+    checkCancelled();
+
+    const badColor = COLOR_FIELDS.map((field) => overlay[field]).find((c) => c && !parseColor(c));
+    if (badColor) {
+      fail(new PdfEditError("INVALID_COLOR", `"${badColor}" is not a valid color`, { index }));
+      continue;
+    }
+
+    let embedded = null;
+    if (
+      (overlay.type === "pdf" && overlay.pdfData) ||
+      (overlay.type === "image" && overlay.imageData)
+    ) {
+      try {
+        embedded = await getEmbedded(overlay);
+      } catch (e) {
+        fail(
+          overlay.type === "pdf"
+            ? new PdfEditError("PDF_STAMP_INVALID", "The stamp PDF can't be read", { index })
+            : new PdfEditError("IMAGE_DECODE_FAILED", "The image can't be decoded", { index })
+        );
+        continue;
+      }
+    }
+
+    const targets = overlayPages(overlay, pages.length);
+
+    // Text is encoded for every page before any is drawn, so a font that
+    // can't draw it is reported on the first page it fails on
+    if (overlay.type === "text") {
+      const font = await getFont(standardFontName(overlay));
+      const badPage = targets.find((pageNumber, ordinal) => {
+        const text = overlayText(overlay, baseVars, pageNumber, ordinal, options.batesOffset);
+        try {
+          text.split(/\r\n|\r|\n/).forEach((line) => font.encodeText(line));
+          return false;
+        } catch (e) {
+          if (!isEncodingError(e)) throw e;
+          return true;
+        }
       });
+      if (badPage) {
+        fail(
+          new PdfEditError("TEXT_ENCODING_FAILED", "The font can't draw some of this text", {
+            index,
+            page: badPage,
+          })
+        );
+        continue;
+      }
+    }
+
+    // When failures are skipped, the overlay is drawn onto scratch layers
+    // and only merged into its pages once every page has succeeded, so it
+    // ends up either fully applied or not at all
+    const scratch = new Map();
+    let failed = false;
+    for (const [ordinal, pageNumber] of targets.entries()) {
+      checkCancelled();
+      const pageIndex = pageNumber - 1;
+      let page = targetPage(overlay, pageIndex);
+      if (options.continueOnError) {
+        page = createLayer(pdfDoc, pages[pageIndex]);
+        scratch.set(pageIndex, page);
+      }
+      try {
+        await drawOverlay(overlay, page, pageNumber, ordinal, embedded);
+      } catch (e) {
+        fail(new PdfEditError("OVERLAY_FAILED", e.message, { index, page: pageNumber }));
+        failed = true;
+        break;
+      }
+    }
+    for (const [pageIndex, layer] of scratch) {
+      if (failed) {
+        discardLayer(pdfDoc.context, layer);
+      } else {
+        await placeOverContent(pdfDoc, targetPage(overlay, pageIndex), layer);
+      }
     }
   }
 
+  for (const [pageIndex, layer] of layers) {
+    await placeUnderContent(pdfDoc, pages[pageIndex], layer);
+  }
+
+  if (options.pageLabels?.length) applyPageLabels(pdfDoc, options.pageLabels);
+  if (options.sanitize) {
+    const removed = sanitizeDocument(pdfDoc);
+    options.onSanitize?.(removed);
+  }
+  if (options.scrubMetadata) scrubMetadata(pdfDoc);
+
+  await reportProgress("saving", overlays.length, overlays.length);
+  checkCancelled();
   return await pdfDoc.save();
 }
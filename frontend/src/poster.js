@@ -0,0 +1,104 @@
+import { PDFDocument, PageSizes, degrees, rgb } from "pdf-lib";
+import { standardFontName } from "./fonts";
+
+export const SHEET_SIZES = {
+  A4: { label: "A4", size: PageSizes.A4 },
+  A3: { label: "A3", size: PageSizes.A3 },
+  Letter: { label: "US Letter", size: PageSizes.Letter },
+};
+
+const MM = 72 / 25.4;
+const MARK_COLOR = rgb(0.6, 0.6, 0.6);
+
+// Sheets needed along one side of the poster when neighbours overlap
+function sheetsAlong(length, sheet, overlap) {
+  return Math.max(1, Math.ceil((length - overlap) / (sheet - overlap)));
+}
+
+/**
+ * Tile 1-based page `pageNumber` across printer sheets as a new PDF, at
+ * `scale` (1 = actual size) and as the page is displayed. Neighbouring
+ * sheets repeat `overlapMm` of the page so they can be trimmed and glued;
+ * dashed lines mark where the overlap starts, and each sheet is labelled
+ * with its row and column. Sheets are portrait or landscape, whichever
+ * needs fewer. Returns { bytes, rows, columns }.
+ */
+export async function tilePage(pdfBytes, password, pageNumber, options = {}) {
+  const { sheet = "A4", overlapMm = 10, scale = 1 } = options;
+  const source = await PDFDocument.load(pdfBytes, password ? { password } : {});
+  const page = source.getPage(pageNumber - 1);
+  const box = page.getCropBox();
+  const angle = ((page.getRotation().angle % 360) + 360) % 360;
+  const sideways = angle === 90 || angle === 270;
+  const posterWidth = (sideways ? box.height : box.width) * scale;
+  const posterHeight = (sideways ? box.width : box.height) * scale;
+
+  const overlap = overlapMm * MM;
+  const [a, b] = (SHEET_SIZES[sheet] || SHEET_SIZES.A4).size;
+  if (overlap * 2 >= Math.min(a, b)) throw new Error("The overlap must be under half the sheet");
+  const layouts = [
+    [a, b],
+    [b, a],
+  ].map(([width, height]) => ({
+    width,
+    height,
+    columns: sheetsAlong(posterWidth, width, overlap),
+    rows: sheetsAlong(posterHeight, height, overlap),
+  }));
+  const { width, height, columns, rows } = layouts.reduce((best, l) =>
+    l.columns * l.rows < best.columns * best.rows ? l : best
+  );
+
+  const poster = await PDFDocument.create();
+  const font = await poster.embedFont(standardFontName({}));
+  const embedded = await poster.embedPage(page, {
+    left: box.x,
+    bottom: box.y,
+    right: box.x + box.width,
+    top: box.y + box.height,
+  });
+
+  // drawPage rotates counter-clockwise about its anchor, so the anchor
+  // moves to whichever corner ends up bottom-left
+  const ccw = (360 - angle) % 360;
+  const w = box.width * scale;
+  const h = box.height * scale;
+  const anchor = { 0: [0, 0], 90: [h, 0], 180: [w, h], 270: [0, w] }[ccw];
+
+  // The grid of sheets is centred on the poster
+  const stepX = width - overlap;
+  const stepY = height - overlap;
+  const gridLeft = -(columns * stepX + overlap - posterWidth) / 2;
+  const gridTop = posterHeight + (rows * stepY + overlap - posterHeight) / 2;
+
+  for (let row = 0; row < rows; row++) {
+    for (let column = 0; column < columns; column++) {
+      const tile = poster.addPage([width, height]);
+      const left = gridLeft + column * stepX;
+      const bottom = gridTop - row * stepY - height;
+      tile.drawPage(embedded, {
+        x: anchor[0] - left,
+        y: anchor[1] - bottom,
+        xScale: scale,
+        yScale: scale,
+        rotate: degrees(ccw),
+      });
+
+      const mark = (start, end) =>
+        tile.drawLine({ start, end, thickness: 0.5, color: MARK_COLOR, dashArray: [4, 4] });
+      if (column > 0) mark({ x: overlap, y: 0 }, { x: overlap, y: height });
+      if (column < columns - 1) mark({ x: width - overlap, y: 0 }, { x: width - overlap, y: height });
+      if (row > 0) mark({ x: 0, y: height - overlap }, { x: width, y: height - overlap });
+      if (row < rows - 1) mark({ x: 0, y: overlap }, { x: width, y: overlap });
+      tile.drawText(`Row ${row + 1}/${rows}, column ${column + 1}/${columns}`, {
+        x: 6,
+        y: 6,
+        size: 7,
+        font,
+        color: MARK_COLOR,
+      });
+    }
+  }
+
+  return { bytes: await poster.save(), rows, columns };
+}
@@ -0,0 +1,231 @@
+import { PDFName, PDFDict, PDFArray, PDFRef } from "pdf-lib";
+
+// Action types that run code, open other files or programs, or send data
+// out. PDFName.of() interns names, so members compare by identity.
+const UNSAFE_ACTIONS = new Set(
+  ["JavaScript", "Launch", "GoToR", "GoToE", "ImportData", "SubmitForm", "Rendition"].map((n) =>
+    PDFName.of(n)
+  )
+);
+
+const AA = PDFName.of("AA");
+
+function lookupDict(dict, key) {
+  const value = dict.lookup(PDFName.of(key));
+  return value instanceof PDFDict ? value : undefined;
+}
+
+// An action is unsafe if it, or any action chained after it in /Next, has
+// an unsafe type
+function isUnsafeAction(context, action, seen = new Set()) {
+  if (!(action instanceof PDFDict) || seen.has(action)) return false;
+  seen.add(action);
+  if (UNSAFE_ACTIONS.has(action.lookup(PDFName.of("S")))) return true;
+
+  const next = action.lookup(PDFName.of("Next"));
+  const chained = next instanceof PDFArray ? next.asArray().map((a) => context.lookup(a)) : [next];
+  return chained.some((a) => isUnsafeAction(context, a, seen));
+}
+
+// pdf-lib writes every indirect object it has parsed, referenced or not,
+// so removed content has to be deleted as objects, not just unlinked
+function deleteObject(context, value) {
+  if (value instanceof PDFRef) context.delete(value);
+}
+
+// An action with its script stream, embedded target file and /Next chain
+function deleteAction(context, value, seen = new Set()) {
+  const action = context.lookup(value);
+  if (!(action instanceof PDFDict) || seen.has(action)) return;
+  seen.add(action);
+  deleteObject(context, action.get(PDFName.of("JS")));
+  deleteFileSpec(context, action.get(PDFName.of("F")));
+  const next = action.lookup(PDFName.of("Next"));
+  const chained = next instanceof PDFArray ? next.asArray() : [action.get(PDFName.of("Next"))];
+  chained.forEach((a) => deleteAction(context, a, seen));
+  deleteObject(context, value);
+}
+
+// An additional-actions (/AA) dictionary and every action in it
+function deleteAdditionalActions(context, value) {
+  const aa = context.lookup(value);
+  if (aa instanceof PDFDict) {
+    for (const [, action] of aa.entries()) deleteAction(context, action);
+  }
+  deleteObject(context, value);
+}
+
+// A file specification and the embedded file streams under its /EF.
+// Plain string file names have nothing to delete.
+function deleteFileSpec(context, value) {
+  const spec = context.lookup(value);
+  if (!(spec instanceof PDFDict)) return;
+  const ef = spec.lookup(PDFName.of("EF"));
+  if (ef instanceof PDFDict) {
+    for (const [, stream] of ef.entries()) deleteObject(context, stream);
+    deleteObject(context, spec.get(PDFName.of("EF")));
+  }
+  deleteObject(context, value);
+}
+
+// A name tree: every node reached through /Kids, and each value in the
+// leaves' /Names [key value ...] arrays via deleteValue
+function deleteNameTree(context, value, deleteValue, seen = new Set()) {
+  const node = context.lookup(value);
+  if (!(node instanceof PDFDict) || seen.has(node)) return;
+  seen.add(node);
+  const names = node.lookup(PDFName.of("Names"));
+  if (names instanceof PDFArray) {
+    for (let i = 1; i < names.size(); i += 2) deleteValue(context, names.get(i));
+    deleteObject(context, node.get(PDFName.of("Names")));
+  }
+  const kids = node.lookup(PDFName.of("Kids"));
+  if (kids instanceof PDFArray) {
+    kids.asArray().forEach((kid) => deleteNameTree(context, kid, deleteValue, seen));
+    deleteObject(context, node.get(PDFName.of("Kids")));
+  }
+  deleteObject(context, value);
+}
+
+const plural = (n, one, many) => `${n} ${n === 1 ? one : many}`;
+
+/**
+ * Strip active content from a loaded document: JavaScript, document open and
+ * event actions, launch / remote-go-to / submit actions on links and form
+ * widgets, embedded files and XFA forms (which may carry scripts).
+ *
+ * Plain URI links are kept; they only open when clicked and are visible to
+ * the reader. Returns human-readable descriptions of what was removed.
+ */
+export function sanitizeDocument(pdfDoc) {
+  const { catalog, context } = pdfDoc;
+  const removed = [];
+
+  // An OpenAction that is only a destination (an array) just sets the page
+  // the viewer opens at, so it is left alone
+  if (lookupDict(catalog, "OpenAction")) {
+    deleteAction(context, catalog.get(PDFName.of("OpenAction")));
+    catalog.delete(PDFName.of("OpenAction"));
+    removed.push("document open action");
+  }
+  if (catalog.has(AA)) {
+    deleteAdditionalActions(context, catalog.get(AA));
+    catalog.delete(AA);
+    removed.push("document event actions");
+  }
+
+  const names = lookupDict(catalog, "Names");
+  for (const [key, label, deleteValue] of [
+    ["JavaScript", "document-level scripts", deleteAction],
+    ["EmbeddedFiles", "embedded files", deleteFileSpec],
+  ]) {
+    if (names?.has(PDFName.of(key))) {
+      deleteNameTree(context, names.get(PDFName.of(key)), deleteValue);
+      names.delete(PDFName.of(key));
+      removed.push(label);
+    }
+  }
+
+  // /XFA is a stream, or an array of [packet-name stream ...] pairs
+  const acroForm = lookupDict(catalog, "AcroForm");
+  if (acroForm?.has(PDFName.of("XFA"))) {
+    const xfa = acroForm.lookup(PDFName.of("XFA"));
+    if (xfa instanceof PDFArray) xfa.asArray().forEach((item) => deleteObject(context, item));
+    deleteObject(context, acroForm.get(PDFName.of("XFA")));
+    acroForm.delete(PDFName.of("XFA"));
+    removed.push("XFA form data");
+  }
+
+  let pageActions = 0;
+  let annotActions = 0;
+  let attachments = 0;
+  for (const page of pdfDoc.getPages()) {
+    if (page.node.has(AA)) {
+      deleteAdditionalActions(context, page.node.get(AA));
+      page.node.delete(AA);
+      pageActions++;
+    }
+
+    const annots = page.node.Annots();
+    if (!annots) continue;
+    // Attachments go with their file streams and the popups showing
+    // their notes, which would otherwise point at a deleted parent
+    const doomed = new Set();
+    for (let i = annots.size() - 1; i >= 0; i--) {
+      const annot = context.lookup(annots.get(i));
+      if (!(annot instanceof PDFDict)) continue;
+
+      if (annot.lookup(PDFName.of("Subtype")) === PDFName.of("FileAttachment")) {
+        deleteFileSpec(context, annot.get(PDFName.of("FS")));
+        doomed.add(annots.get(i));
+        const popup = annot.get(PDFName.of("Popup"));
+        if (popup instanceof PDFRef) doomed.add(popup);
+        attachments++;
+        continue;
+      }
+      if (annot.has(AA)) {
+        deleteAdditionalActions(context, annot.get(AA));
+        annot.delete(AA);
+        annotActions++;
+      }
+      if (isUnsafeAction(context, annot.lookup(PDFName.of("A")))) {
+        deleteAction(context, annot.get(PDFName.of("A")));
+        annot.delete(PDFName.of("A"));
+        annotActions++;
+      }
+    }
+    for (let i = annots.size() - 1; i >= 0; i--) {
+      if (doomed.has(annots.get(i))) annots.remove(i);
+    }
+    doomed.forEach((ref) => deleteObject(context, ref));
+  }
+  if (pageActions) removed.push(plural(pageActions, "page action", "page actions"));
+  if (annotActions) removed.push(plural(annotActions, "link/field action", "link/field actions"));
+  if (attachments) removed.push(plural(attachments, "file attachment", "file attachments"));
+
+  return removed;
+}
+
+/**
+ * Remove identifying metadata: the Info dictionary (author, creator tool,
+ * dates), XMP streams on the document and its pages, embedded page
+ * thumbnails, application private data, and author names on markup
+ * annotations.
+ *
+ * The document must be loaded with updateMetadata: false, or pdf-lib
+ * writes a fresh Producer and dates back in. Earlier incremental-update
+ * revisions are dropped anyway because pdf-lib rewrites the whole file.
+ */
+export function scrubMetadata(pdfDoc) {
+  const { catalog, context } = pdfDoc;
+
+  // The objects must go as well as the keys pointing at them (see
+  // deleteObject)
+  const drop = (dict, key) => {
+    deleteObject(context, dict.get(PDFName.of(key)));
+    dict.delete(PDFName.of(key));
+  };
+
+  if (context.trailerInfo.Info instanceof PDFRef) context.delete(context.trailerInfo.Info);
+  context.trailerInfo.Info = undefined;
+
+  drop(catalog, "Metadata");
+  drop(catalog, "PieceInfo");
+
+  for (const page of pdfDoc.getPages()) {
+    ["Metadata", "PieceInfo", "Thumb"].forEach((key) => drop(page.node, key));
+
+    // On widgets /T is the form field name, not an author, so keep it
+    const annots = page.node.Annots();
+    if (!annots) continue;
+    for (let i = 0; i < annots.size(); i++) {
+      const annot = context.lookup(annots.get(i));
+      if (
+        annot instanceof PDFDict &&
+        annot.lookup(PDFName.of("Subtype")) !== PDFName.of("Widget")
+      ) {
+        annot.delete(PDFName.of("T"));
+      }
+    }
+  }
+}
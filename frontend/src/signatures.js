@@ -0,0 +1,29 @@
+import { readList, writeList } from "./storage";
+
+/**
+ * The user's saved signature images, persisted in localStorage so the same
+ * signature can be placed on any document without re-uploading it.
+ */
+const STORAGE_KEY = "pdf-editor:signatures";
+
+export function loadSignatures() {
+  return readList(STORAGE_KEY);
+}
+
+/**
+ * Add an image (data URL) to the library unless the same image is already
+ * saved. Returns the updated signature list.
+ */
+export function saveSignature(name, imageData) {
+  const signatures = loadSignatures();
+  if (signatures.some((s) => s.imageData === imageData)) return signatures;
+  signatures.push({ id: Date.now(), name, imageData });
+  writeList(STORAGE_KEY, signatures);
+  return signatures;
+}
+
+export function deleteSignature(id) {
+  const signatures = loadSignatures().filter((s) => s.id !== id);
+  writeList(STORAGE_KEY, signatures);
+  return signatures;
+}
@@ -0,0 +1,99 @@
+import { PDFDocument, PDFName, PDFDict, PDFArray, PDFRef, PDFStream } from "pdf-lib";
+import { sanitizeFileName } from "./fileNames";
+
+/**
+ * Page ranges for splitting at top-level bookmarks (as extractOutline
+ * returns them). Each bookmark that resolves to a page starts a part that
+ * runs until the next one starts; a bookmark on the same page as an earlier
+ * one adds no part. Pages before the first bookmark become a part of their
+ * own. Returns [{ title, from, to }] with 1-based, inclusive page numbers.
+ */
+export function bookmarkRanges(outline, pageCount) {
+  const starts = outline
+    .filter((item) => Number.isInteger(item.destPage) && item.destPage >= 1 && item.destPage <= pageCount)
+    .map((item) => ({ title: item.title, from: item.destPage }))
+    .sort((a, b) => a.from - b.from)
+    .filter((item, i, all) => i === 0 || item.from !== all[i - 1].from);
+  if (starts.length === 0) return [];
+  if (starts[0].from > 1) starts.unshift({ title: `Pages 1-${starts[0].from - 1}`, from: 1 });
+  return starts.map((part, i) => ({
+    ...part,
+    to: i + 1 < starts.length ? starts[i + 1].from - 1 : pageCount,
+  }));
+}
+
+// The page an annotation's link jumps to, when given as an explicit
+// destination ([pageRef /XYZ ...]) directly or through a GoTo action
+function linkTarget(annot) {
+  let dest = annot.lookup(PDFName.of("Dest"));
+  const action = annot.lookup(PDFName.of("A"));
+  if (!dest && action instanceof PDFDict && action.get(PDFName.of("S")) === PDFName.of("GoTo")) {
+    dest = action.lookup(PDFName.of("D"));
+  }
+  return dest instanceof PDFArray ? dest.get(0) : undefined;
+}
+
+/**
+ * Remove links to pages that didn't come along into `pdfDoc`. Copying a
+ * page copies whatever its links point at, so without this each part
+ * would carry copies of the pages it links to elsewhere in the original.
+ */
+function dropOutsideLinks(pdfDoc) {
+  const { context } = pdfDoc;
+  const own = new Set(pdfDoc.getPages().map((page) => page.ref));
+  for (const page of pdfDoc.getPages()) {
+    const annots = page.node.Annots();
+    if (!annots) continue;
+    for (let i = annots.size() - 1; i >= 0; i--) {
+      const annot = context.lookup(annots.get(i));
+      const target = annot instanceof PDFDict ? linkTarget(annot) : undefined;
+      if (target instanceof PDFRef && !own.has(target)) annots.remove(i);
+    }
+  }
+}
+
+// Delete every object the saved file could not reach from its trailer
+function dropUnreachable(pdfDoc) {
+  const { context } = pdfDoc;
+  const seen = new Set();
+  const visit = (obj) => {
+    if (obj instanceof PDFRef) {
+      if (seen.has(obj)) return;
+      seen.add(obj);
+      visit(context.lookup(obj));
+    } else if (obj instanceof PDFDict) {
+      obj.values().forEach(visit);
+    } else if (obj instanceof PDFArray) {
+      obj.asArray().forEach(visit);
+    } else if (obj instanceof PDFStream) {
+      visit(obj.dict);
+    }
+  };
+  visit(context.trailerInfo.Root);
+  visit(context.trailerInfo.Info);
+  for (const [ref] of context.enumerateIndirectObjects()) {
+    if (!seen.has(ref)) context.delete(ref);
+  }
+}
+
+/**
+ * Copy each of `ranges` (see bookmarkRanges) out of the document into a
+ * PDF of its own. Returns [{ name, bytes }] in page order; names are
+ * numbered so they sort in that order and stay unique.
+ */
+export async function splitDocument(pdfBytes, password, ranges) {
+  const source = await PDFDocument.load(pdfBytes, password ? { password } : {});
+  const digits = String(ranges.length).length;
+  const parts = [];
+  for (const [i, { title, from, to }] of ranges.entries()) {
+    const part = await PDFDocument.create();
+    const indices = Array.from({ length: to - from + 1 }, (_, k) => from - 1 + k);
+    for (const page of await part.copyPages(source, indices)) part.addPage(page);
+    part.setTitle(title);
+    dropOutsideLinks(part);
+    dropUnreachable(part);
+    const number = String(i + 1).padStart(digits, "0");
+    parts.push({ name: `${number}-${sanitizeFileName(title)}.pdf`, bytes: await part.save() });
+  }
+  return parts;
+}
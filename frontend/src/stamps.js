@@ -0,0 +1,22 @@
+/**
+ * Built-in stamp glyphs, drawn as vector paths so they render identically
+ * in the editor preview and in the generated PDF regardless of font support.
+ *
+ * Paths are in a unit square (0–1) with the origin at the top-left, matching
+ * both SVG and pdf-lib's drawSvgPath coordinate conventions.
+ */
+export const STAMP_GLYPHS = {
+  check: { label: "✓ Check", path: "M 0.12 0.55 L 0.4 0.82 L 0.88 0.18", fill: false },
+  cross: { label: "✗ Cross", path: "M 0.18 0.18 L 0.82 0.82 M 0.82 0.18 L 0.18 0.82", fill: false },
+  box: { label: "■ Filled box", path: "M 0.1 0.1 L 0.9 0.1 L 0.9 0.9 L 0.1 0.9 Z", fill: true },
+};
+
+// Stroke width of outline glyphs, relative to the stamp size.
+export const STAMP_STROKE = 0.12;
+
+/**
+ * Scale a unit-square glyph path to the given size (in points or pixels).
+ */
+export function scaleStampPath(path, size) {
+  return path.replace(/-?\d*\.?\d+/g, (n) => String(+(parseFloat(n) * size).toFixed(3)));
+}
@@ -0,0 +1,19 @@
+/**
+ * Small helpers for lists persisted as JSON in localStorage.
+ */
+export function readList(key) {
+  try {
+    return JSON.parse(localStorage.getItem(key)) || [];
+  } catch (e) {
+    return [];
+  }
+}
+
+export function writeList(key, list) {
+  try {
+    localStorage.setItem(key, JSON.stringify(list));
+  } catch (e) {
+    // Usually QuotaExceededError from large embedded images
+    throw new Error("Not enough browser storage to save this");
+  }
+}
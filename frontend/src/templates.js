@@ -0,0 +1,32 @@
+import { readList, writeList } from "./storage";
+
+/**
+ * Named overlay templates persisted in localStorage, so the same stamp
+ * layout can be reapplied to any number of documents.
+ */
+const STORAGE_KEY = "pdf-editor:templates";
+
+export function loadTemplates() {
+  return readList(STORAGE_KEY);
+}
+
+/**
+ * Save the given overlays under `name`, replacing any template with the
+ * same name. Returns the updated template list.
+ */
+export function saveTemplate(name, overlays) {
+  const templates = loadTemplates().filter((t) => t.name !== name);
+  templates.push({
+    id: Date.now(),
+    name,
+    overlays: overlays.map(({ id, ...rest }) => rest),
+  });
+  writeList(STORAGE_KEY, templates);
+  return templates;
+}
+
+export function deleteTemplate(id) {
+  const templates = loadTemplates().filter((t) => t.id !== id);
+  writeList(STORAGE_KEY, templates);
+  return templates;
+}
@@ -0,0 +1,103 @@
+import { PDFDocument, PageSizes, rgb } from "pdf-lib";
+import { standardFontName } from "./fonts";
+import { wrapText } from "./pdfGenerator";
+
+export const PAGE_SIZES = {
+  A4: { label: "A4", size: PageSizes.A4 },
+  Letter: { label: "US Letter", size: PageSizes.Letter },
+  Legal: { label: "US Legal", size: PageSizes.Legal },
+};
+
+const MM = 72 / 25.4;
+const HEADING_SCALE = [2, 1.5, 1.25, 1.1, 1, 1];
+
+/**
+ * Split text into blocks to lay out. Plain text keeps every line break.
+ * Markdown mode understands the subset notices need: #-headings, "-", "*"
+ * and "1." list items, and blank-line separated paragraphs whose lines are
+ * joined. Inline emphasis and code markers are dropped, since the standard
+ * fonts can't mix styles within one wrapped line.
+ */
+function toBlocks(text, markdown) {
+  const lines = text.replace(/\r\n?/g, "\n").split("\n");
+  if (!markdown) return lines.map((line) => ({ text: line }));
+
+  const blocks = [];
+  let paragraph = null;
+  const inline = (s) => s.replace(/(\*\*|__|`)/g, "").replace(/(^|\s)[*_](\S[^*_]*)[*_]/g, "$1$2");
+
+  for (const line of lines) {
+    const heading = line.match(/^(#{1,6})\s+(.*)$/);
+    const item = line.match(/^\s*([-*+]|\d+[.)])\s+(.*)$/);
+    if (heading) {
+      blocks.push({ text: inline(heading[2]), heading: heading[1].length });
+      paragraph = null;
+    } else if (item) {
+      const marker = /\d/.test(item[1]) ? item[1] : "•";
+      blocks.push({ text: inline(item[2]), marker });
+      paragraph = null;
+    } else if (!line.trim()) {
+      if (blocks.length && !blocks[blocks.length - 1].gap) blocks.push({ text: "", gap: true });
+      paragraph = null;
+    } else if (paragraph) {
+      paragraph.text += ` ${inline(line.trim())}`;
+    } else {
+      paragraph = { text: inline(line.trim()) };
+      blocks.push(paragraph);
+    }
+  }
+  return blocks;
+}
+
+/**
+ * Render plain text or simple Markdown into a new paginated PDF using the
+ * standard fonts. Options: pageSize (key of PAGE_SIZES), marginMm,
+ * fontFamily (key of FONT_FAMILIES), fontSize and markdown. Characters the
+ * standard fonts can't encode are replaced with "?".
+ */
+export async function createPdfFromText(text, options = {}) {
+  const { pageSize = "A4", marginMm = 20, fontFamily = "Helvetica", fontSize = 11, markdown } =
+    options;
+  const [width, height] = (PAGE_SIZES[pageSize] || PAGE_SIZES.A4).size;
+  const margin = marginMm * MM;
+  const pdfDoc = await PDFDocument.create();
+
+  const regular = await pdfDoc.embedFont(standardFontName({ fontFamily }));
+  const bold = await pdfDoc.embedFont(standardFontName({ fontFamily, bold: true }));
+  const supported = new Set(regular.getCharacterSet());
+  const encodable = (s) =>
+    [...s].map((c) => (supported.has(c.codePointAt(0)) ? c : "?")).join("");
+
+  let page = null;
+  let y = 0;
+  const newPage = () => {
+    page = pdfDoc.addPage([width, height]);
+    y = height - margin;
+  };
+  newPage();
+
+  for (const block of toBlocks(text, markdown)) {
+    const size = block.heading ? fontSize * HEADING_SCALE[block.heading - 1] : fontSize;
+    const font = block.heading ? bold : regular;
+    const indent = block.marker ? size * 1.5 : 0;
+    const lineGap = size * 1.3;
+
+    // Headings get breathing room above, but not at the top of a page
+    if (block.heading && y < height - margin) y -= size * 0.5;
+
+    const maxWidth = width - 2 * margin - indent;
+    const lines = block.text ? wrapText(encodable(block.text), font, size, maxWidth) : [""];
+    for (const [i, line] of lines.entries()) {
+      if (y - size < margin) newPage();
+      y -= size;
+      if (block.marker && i === 0) {
+        page.drawText(encodable(block.marker), { x: margin, y, size, font, color: rgb(0, 0, 0) });
+      }
+      if (line) page.drawText(line, { x: margin + indent, y, size, font, color: rgb(0, 0, 0) });
+      y -= lineGap - size;
+    }
+    if (block.heading) y -= size * 0.3;
+  }
+
+  return await pdfDoc.save();
+}
@@ -0,0 +1,141 @@
+import { rgb } from "pdf-lib";
+import { standardFontName } from "./fonts";
+
+const MM = 72 / 25.4;
+const MARGIN = 20 * MM;
+const TITLE_SIZE = 18;
+const ENTRY_SIZE = 11;
+const LINE_HEIGHT = ENTRY_SIZE * 1.6;
+const INDENT = 14;
+
+/**
+ * Flatten a bookmark tree (as extractOutline returns it) into contents
+ * entries [{ title, page, level }], down to `depth` levels. Bookmarks that
+ * don't lead to a page of the document are left out, but their children
+ * are still listed.
+ */
+export function outlineEntries(outline, depth = 1, level = 0) {
+  if (level >= depth) return [];
+  return outline.flatMap((item) => [
+    ...(Number.isInteger(item.destPage) ? [{ title: item.title, page: item.destPage, level }] : []),
+    ...outlineEntries(item.children || [], depth, level + 1),
+  ]);
+}
+
+/**
+ * Parse typed contents entries, one per line as "Title | page"; every two
+ * spaces of indentation nest an entry one level deeper. Blank lines are
+ * skipped. A line that doesn't fit the pattern, or names a page outside
+ * 1..totalPages, throws an error naming the line.
+ */
+export function parseTocEntries(text, totalPages) {
+  const entries = [];
+  text
+    .replace(/\r\n?/g, "\n")
+    .split("\n")
+    .forEach((line, i) => {
+      if (!line.trim()) return;
+      const match = line.match(/^(\s*)(.*?)\s*\|\s*(\d+)\s*$/);
+      if (!match || !match[2]) throw new Error(`Line ${i + 1}: expected "Title | page"`);
+      const page = parseInt(match[3]);
+      if (page < 1 || page > totalPages) throw new Error(`Line ${i + 1}: there is no page ${page}`);
+      const indent = match[1].replace(/\t/g, "  ").length;
+      entries.push({ title: match[2], page, level: Math.floor(indent / 2) });
+    });
+  return entries;
+}
+
+// Shorten text with an ellipsis until it fits in maxWidth
+function fitText(text, font, size, maxWidth) {
+  if (font.widthOfTextAtSize(text, size) <= maxWidth) return text;
+  let fitted = text;
+  while (fitted && font.widthOfTextAtSize(`${fitted}…`, size) > maxWidth) {
+    fitted = fitted.slice(0, -1);
+  }
+  return `${fitted.trimEnd()}…`;
+}
+
+/**
+ * Lay out `entries` (see outlineEntries) as contents pages and insert them
+ * in front of 1-based page `before`; pageCount + 1 appends them. Each line
+ * links to its page and shows the number that page has once the contents
+ * are in. The new pages take the displayed size of the page they precede.
+ * Characters the standard fonts can't encode are replaced with "?".
+ * Returns the number of pages inserted.
+ */
+export async function insertTableOfContents(pdfDoc, entries, { before = 1, title = "Contents" } = {}) {
+  const pages = pdfDoc.getPages();
+  const at = Math.min(Math.max(1, before), pages.length + 1) - 1;
+  const model = pages[Math.min(at, pages.length - 1)];
+  const { width: w, height: h } = model.getSize();
+  const [width, height] = model.getRotation().angle % 180 ? [h, w] : [w, h];
+
+  const regular = await pdfDoc.embedFont(standardFontName({}));
+  const bold = await pdfDoc.embedFont(standardFontName({ bold: true }));
+  const supported = new Set(regular.getCharacterSet());
+  const encodable = (s) =>
+    [...s].map((c) => (supported.has(c.codePointAt(0)) ? c : "?")).join("");
+
+  // Paginate first: the printed numbers depend on how many pages the
+  // contents themselves take
+  const titleBlock = title ? TITLE_SIZE * 2 : 0;
+  const chunks = [];
+  for (let i = 0; i < entries.length || chunks.length === 0; ) {
+    const room = height - 2 * MARGIN - (chunks.length === 0 ? titleBlock : 0);
+    const fit = Math.max(1, Math.floor(room / LINE_HEIGHT));
+    chunks.push(entries.slice(i, i + fit));
+    i += fit;
+  }
+  const pageNumber = (page) => (page > at ? page + chunks.length : page);
+
+  const { context } = pdfDoc;
+  const black = rgb(0, 0, 0);
+  const right = width - MARGIN;
+  chunks.forEach((chunk, k) => {
+    const page = pdfDoc.insertPage(at + k, [width, height]);
+    let y = height - MARGIN;
+    if (k === 0 && title) {
+      y -= TITLE_SIZE;
+      page.drawText(encodable(title), { x: MARGIN, y, size: TITLE_SIZE, font: bold, color: black });
+      y -= TITLE_SIZE;
+    }
+
+    for (const entry of chunk) {
+      y -= LINE_HEIGHT;
+      const x = MARGIN + entry.level * INDENT;
+      const number = String(pageNumber(entry.page));
+      const numberX = right - regular.widthOfTextAtSize(number, ENTRY_SIZE);
+      const label = fitText(encodable(entry.title), regular, ENTRY_SIZE, numberX - ENTRY_SIZE * 2 - x);
+      page.drawText(label, { x, y, size: ENTRY_SIZE, font: regular, color: black });
+      page.drawText(number, { x: numberX, y, size: ENTRY_SIZE, font: regular, color: black });
+
+      // Dot leader from the title to the page number
+      const dotWidth = regular.widthOfTextAtSize(" .", ENTRY_SIZE);
+      const leaderEnd = numberX - ENTRY_SIZE / 2;
+      const dots = Math.floor(
+        (leaderEnd - x - regular.widthOfTextAtSize(label, ENTRY_SIZE) - ENTRY_SIZE / 2) / dotWidth
+      );
+      if (dots > 0) {
+        page.drawText(" .".repeat(dots), {
+          x: leaderEnd - dots * dotWidth,
+          y,
+          size: ENTRY_SIZE,
+          font: regular,
+          color: rgb(0.5, 0.5, 0.5),
+        });
+      }
+
+      const link = context.register(
+        context.obj({
+          Type: "Annot",
+          Subtype: "Link",
+          Rect: [x, y - ENTRY_SIZE * 0.3, right, y + ENTRY_SIZE],
+          Border: [0, 0, 0],
+          Dest: [pages[entry.page - 1].ref, "XYZ", null, null, null],
+        })
+      );
+      page.node.addAnnot(link);
+    }
+  });
+  return chunks.length;
+}
@@ -0,0 +1,29 @@
+/**
+ * Coordinate units offered for precise positioning, in PDF points per unit.
+ * Overlays are always stored as percentages of the page; these helpers only
+ * convert for display and input.
+ */
+export const UNITS = {
+  percent: { label: "%", points: null },
+  pt: { label: "pt", points: 1 },
+  mm: { label: "mm", points: 72 / 25.4 },
+  in: { label: "in", points: 72 },
+};
+
+/**
+ * Convert a stored percentage along a page dimension (in points) to `unit`.
+ */
+export function fromPercent(percent, unit, pageDimension) {
+  const { points } = UNITS[unit] || UNITS.percent;
+  if (!points) return percent;
+  return ((percent / 100) * pageDimension) / points;
+}
+
+/**
+ * Convert a value in `unit` along a page dimension (in points) to a percentage.
+ */
+export function toPercent(value, unit, pageDimension) {
+  const { points } = UNITS[unit] || UNITS.percent;
+  if (!points) return value;
+  return ((value * points) / pageDimension) * 100;
+}
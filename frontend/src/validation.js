@@ -0,0 +1,109 @@
+import { PDFDocument } from "pdf-lib";
+import { FONT_FAMILIES, standardFontName } from "./fonts";
+import { overlayPages } from "./pages";
+import { loadImage, baseVariables, overlayText } from "./pdfGenerator";
+import { parseColor, COLOR_FIELDS } from "./colors";
+
+const SIZED_TYPES = new Set(["image", "pdf", "highlight"]);
+
+/**
+ * Check overlays without generating anything, so problems can be shown
+ * before the user exports. Returns diagnostics as
+ * { index, severity: "error" | "warning", code, message }, where index is
+ * the overlay's position in the array. Errors would make generation fail
+ * or draw nothing; warnings flag likely mistakes.
+ *
+ * Text is checked as it will be drawn, with its {variables} expanded for
+ * every page it's on and, for a mail merge, every one of `records`.
+ */
+export async function validateOverlays(overlays, pageCount, { fileName, records } = {}) {
+  const diagnostics = [];
+  const report = (index, severity, code, message) =>
+    diagnostics.push({ index, severity, code, message });
+
+  // Standard fonts are embedded into a scratch document once per font to
+  // learn which characters each can encode
+  const scratch = await PDFDocument.create();
+  const charsets = new Map();
+  const charset = async (fontName) => {
+    if (!charsets.has(fontName)) {
+      const font = await scratch.embedFont(fontName);
+      charsets.set(fontName, new Set(font.getCharacterSet()));
+    }
+    return charsets.get(fontName);
+  };
+
+  const variableSets = (records?.length ? records : [undefined]).map((variables) =>
+    baseVariables(pageCount, { fileName, variables })
+  );
+
+  for (const [index, o] of overlays.entries()) {
+    if (overlayPages(o, pageCount).length === 0) {
+      const message = o.pages
+        ? `Page selection "${o.pages}" matches no pages (document has ${pageCount})`
+        : `Page ${o.page} doesn't exist (document has ${pageCount})`;
+      report(index, "error", "INVALID_PAGE", message);
+    }
+
+    if (![o.x, o.y].every((v) => Number.isFinite(v) && v >= 0 && v <= 100)) {
+      report(index, "error", "COORDINATE_OUT_OF_RANGE", "Position is outside the page");
+    }
+    if (SIZED_TYPES.has(o.type)) {
+      if (!(o.width > 0 && o.height > 0)) {
+        report(index, "error", "INVALID_SIZE", "Width and height must be greater than zero");
+      } else if (o.x + o.width > 100.5 || o.y + o.height > 100.5) {
+        report(index, "warning", "OUTSIDE_PAGE", "Extends past the edge of the page");
+      }
+    }
+
+    for (const field of COLOR_FIELDS) {
+      if (o[field] && !parseColor(o[field])) {
+        report(index, "error", "INVALID_COLOR", `"${o[field]}" is not a valid color`);
+      }
+    }
+
+    if (o.type === "text") {
+      if (o.fontFamily && !FONT_FAMILIES[o.fontFamily]) {
+        report(index, "error", "UNSUPPORTED_FONT", `Font "${o.fontFamily}" is not available`);
+        continue;
+      }
+      if (!(o.text || "").trim()) {
+        report(index, "warning", "EMPTY_TEXT", "Text is empty");
+        continue;
+      }
+      const chars = new Set();
+      for (const vars of variableSets) {
+        overlayPages(o, pageCount).forEach((pageNumber, ordinal) => {
+          for (const c of overlayText(o, vars, pageNumber, ordinal)) chars.add(c);
+        });
+      }
+      const supported = await charset(standardFontName(o));
+      // Line breaks are consumed by wrapText; every other character,
+      // tabs and special spaces included, has to be in the font
+      const missing = [...chars].filter((c) => c !== "\n" && c !== "\r" && !supported.has(c.codePointAt(0)));
+      if (missing.length) {
+        // Invisible characters are named by code point so they can be found
+        const shown = (c) =>
+          /\s/.test(c) ? `U+${c.codePointAt(0).toString(16).toUpperCase().padStart(4, "0")}` : c;
+        const sample = missing.slice(0, 10).map(shown).join(" ");
+        report(index, "error", "TEXT_ENCODING_FAILED", `The font can't draw: ${sample}`);
+      }
+    } else if (o.type === "image") {
+      try {
+        await loadImage(o.imageData);
+      } catch (e) {
+        report(index, "error", "IMAGE_DECODE_FAILED", "The image can't be decoded");
+      }
+    } else if (o.type === "pdf") {
+      try {
+        const source = await PDFDocument.load(o.pdfData);
+        if ((o.sourcePage || 1) > source.getPageCount()) {
+          report(index, "error", "INVALID_PAGE", `The stamp PDF has no page ${o.sourcePage}`);
+        }
+      } catch (e) {
+        report(index, "error", "PDF_STAMP_INVALID", "The stamp PDF can't be read");
+      }
+    }
+  }
+  return diagnostics;
+}
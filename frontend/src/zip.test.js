@@ -0,0 +1,84 @@
+import { test } from "node:test";
+import assert from "node:assert/strict";
+import { createZip } from "./zip.js";
+
+const encoder = new TextEncoder();
+const decoder = new TextDecoder();
+
+// Read the entries back through the central directory, as unzip tools do
+async function readZip(blob) {
+  const bytes = new Uint8Array(await blob.arrayBuffer());
+  const view = new DataView(bytes.buffer);
+  const endAt = bytes.length - 22;
+  assert.equal(view.getUint32(endAt, true), 0x06054b50);
+  const count = view.getUint16(endAt + 10, true);
+  let at = view.getUint32(endAt + 16, true);
+  assert.equal(at + view.getUint32(endAt + 12, true), endAt);
+
+  const entries = [];
+  for (let i = 0; i < count; i++) {
+    assert.equal(view.getUint32(at, true), 0x02014b50);
+    const nameLength = view.getUint16(at + 28, true);
+    const local = view.getUint32(at + 42, true);
+    assert.equal(view.getUint32(local, true), 0x04034b50);
+    const size = view.getUint32(local + 18, true);
+    const dataAt = local + 30 + view.getUint16(local + 26, true);
+    entries.push({
+      name: decoder.decode(bytes.subarray(at + 46, at + 46 + nameLength)),
+      crc: view.getUint32(at + 16, true),
+      flags: view.getUint16(at + 8, true),
+      data: decoder.decode(bytes.subarray(dataAt, dataAt + size)),
+    });
+    at += 46 + nameLength;
+  }
+  return entries;
+}
+
+test("stores files with their names, contents and checksums", async () => {
+  const entries = await readZip(
+    createZip([
+      { name: "a.pdf", data: encoder.encode("hello") },
+      { name: "b.pdf", data: encoder.encode("world!") },
+    ])
+  );
+  assert.deepEqual(
+    entries.map(({ name, data, crc }) => [name, data, crc]),
+    [
+      ["a.pdf", "hello", 0x3610a686],
+      ["b.pdf", "world!", 0x718498e8],
+    ]
+  );
+});
+
+test("flags names as UTF-8", async () => {
+  const [entry] = await readZip(createZip([{ name: "Résumé 履歴.pdf", data: new Uint8Array() }]));
+  assert.equal(entry.name, "Résumé 履歴.pdf");
+  assert.equal(entry.flags & 0x0800, 0x0800);
+});
+
+test("numbers duplicate names", async () => {
+  const data = new Uint8Array([1]);
+  const entries = await readZip(
+    createZip([
+      { name: "a.pdf", data },
+      { name: "a.pdf", data },
+      { name: "a.pdf", data },
+      { name: "notes", data },
+      { name: "notes", data },
+    ])
+  );
+  assert.deepEqual(
+    entries.map((e) => e.name),
+    ["a.pdf", "a (2).pdf", "a (3).pdf", "notes", "notes (2)"]
+  );
+});
+
+test("writes an empty file and an empty archive", async () => {
+  const [entry] = await readZip(createZip([{ name: "empty.pdf", data: new Uint8Array() }]));
+  assert.deepEqual([entry.data, entry.crc], ["", 0]);
+
+  const blob = createZip([]);
+  assert.equal(blob.size, 22);
+  assert.equal(blob.type, "application/zip");
+  assert.deepEqual(await readZip(blob), []);
+});
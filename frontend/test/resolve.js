@@ -0,0 +1,10 @@
+// Vite resolves relative imports without a file extension ("./pages");
+// Node doesn't, so retry those with ".js" when the tests load them
+export async function resolve(specifier, context, next) {
+  try {
+    return await next(specifier, context);
+  } catch (err) {
+    if (err.code !== "ERR_MODULE_NOT_FOUND" || !/^\.{1,2}\//.test(specifier)) throw err;
+    return next(`${specifier}.js`, context);
+  }
+}